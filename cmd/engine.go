@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/leonhfr/cete/pkg/engine"
+	"github.com/spf13/cobra"
+)
+
+const (
+	engineArg     = "arg"
+	engineEnv     = "env"
+	engineWorkdir = "workdir"
+	jsonOutput    = "json"
+)
+
+type (
+	jsonOption struct {
+		Name    string   `json:"name"`
+		Type    string   `json:"type"`
+		Default string   `json:"default"`
+		Min     string   `json:"min,omitempty"`
+		Max     string   `json:"max,omitempty"`
+		Vars    []string `json:"vars,omitempty"`
+	}
+
+	jsonEngineInfo struct {
+		ID      map[string]string `json:"id"`
+		Options []jsonOption      `json:"options"`
+	}
+)
+
+// engineCmd represents the engine command
+var engineCmd = &cobra.Command{
+	Use:   "engine",
+	Short: "inspect UCI engines",
+}
+
+// engineInfoCmd represents the engine info command
+var engineInfoCmd = &cobra.Command{
+	Use:   "info <engine>",
+	Short: "print the id and options of an engine",
+	Long: `The engine info command starts an engine and prints
+its id and the options it exposes, which can be used
+in the options of a yaml template file.`,
+	Args:    cobra.MatchAll(cobra.ExactArgs(1)),
+	Example: "  cete engine info stockfish --json\n  cete engine info ./lc0 --arg=--weights=t1.pb.gz",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engineArgs, _ := cmd.Flags().GetStringArray(engineArg)
+		workdir, _ := cmd.Flags().GetString(engineWorkdir)
+		env, _ := cmd.Flags().GetStringArray(engineEnv)
+
+		id, options, err := engine.Inspect(engine.Config{
+			Path: args[0],
+			Args: engineArgs,
+			Dir:  workdir,
+			Env:  env,
+		})
+		if err != nil {
+			return err
+		}
+
+		info := newJSONEngineInfo(id, options)
+		if j, _ := cmd.Flags().GetBool(jsonOutput); j {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(info)
+		}
+
+		return printEngineInfo(os.Stdout, info)
+	},
+}
+
+func init() {
+	engineInfoCmd.Flags().StringArray(engineArg, nil, "command-line argument passed to the engine (repeatable)")
+	engineInfoCmd.Flags().String(engineWorkdir, "", "working directory of the engine")
+	engineInfoCmd.Flags().StringArray(engineEnv, nil, "environment variable KEY=VALUE added to the engine environment (repeatable)")
+	engineInfoCmd.Flags().Bool(jsonOutput, false, "print in JSON format")
+	_ = engineInfoCmd.MarkFlagDirname(engineWorkdir)
+	engineCmd.AddCommand(engineInfoCmd)
+	rootCmd.AddCommand(engineCmd)
+}
+
+// newJSONEngineInfo returns the engine id and options sorted by name
+func newJSONEngineInfo(id map[string]string, options map[string]uci.Option) jsonEngineInfo {
+	info := jsonEngineInfo{ID: id, Options: []jsonOption{}}
+	for _, o := range options {
+		info.Options = append(info.Options, jsonOption{
+			Name:    o.Name,
+			Type:    string(o.Type),
+			Default: o.Default,
+			Min:     o.Min,
+			Max:     o.Max,
+			Vars:    o.Vars,
+		})
+	}
+	sort.Slice(info.Options, func(i, j int) bool {
+		return info.Options[i].Name < info.Options[j].Name
+	})
+	return info
+}
+
+// printEngineInfo prints the engine id and the option table
+func printEngineInfo(w io.Writer, info jsonEngineInfo) error {
+	keys := make([]string, 0, len(info.ID))
+	for k := range info.ID {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", k, info.ID[k]); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tDEFAULT\tMIN\tMAX\tVARS")
+	for _, o := range info.Options {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", o.Name, o.Type, o.Default, o.Min, o.Max, strings.Join(o.Vars, ", "))
+	}
+	return tw.Flush()
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"time"
 
 	"github.com/fatih/structs"
@@ -17,13 +19,18 @@ import (
 type (
 	yamlPlayer struct {
 		Engine  string            `yaml:"engine"`
+		Args    []string          `yaml:"args" structs:"-"`
+		Workdir string            `yaml:"workdir" structs:"-"`
+		Env     map[string]string `yaml:"env" structs:"-"`
 		Options map[string]string `yaml:"options"`
+		Ponder  bool              `yaml:"ponder" structs:"-"`
 	}
 
 	yamlInput struct {
-		White yamlPlayer `yaml:"white"`
-		Black yamlPlayer `yaml:"black"`
-		Time  int        `yaml:"time"`
+		White  yamlPlayer `yaml:"white"`
+		Black  yamlPlayer `yaml:"black"`
+		Time   int        `yaml:"time"`
+		PGNOut string     `yaml:"pgn_out" structs:"-"`
 	}
 )
 
@@ -42,16 +49,23 @@ options as flags.`,
 			return err
 		}
 
+		options, err := getOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		if options.pgnOut == "" {
+			options.pgnOut = input.PGNOut
+		}
+
 		return runGame(
 			cmd.Context(),
 			game.Input{
-				WhiteEngine:  input.White.Engine,
-				BlackEngine:  input.Black.Engine,
-				WhiteOptions: input.White.Options,
-				BlackOptions: input.Black.Options,
-				Time:         time.Duration(input.Time * 10e5),
+				White: input.White.player(),
+				Black: input.Black.player(),
+				Time:  time.Duration(input.Time * 10e5),
 			},
-			getOptions(cmd),
+			options,
 		)
 	},
 }
@@ -62,26 +76,77 @@ func init() {
 
 // runGame runs a game
 func runGame(ctx context.Context, input game.Input, options options) error {
-	var g *chess.Game
+	var result *game.Result
 	var err error
 
+	input.LogDir = options.logDir
+	input.Verbosity = options.verbosity
+	input.Console = console(options)
+
 	if options.broadcast {
-		g, err = game.RunWithLive(ctx, input, options.port)
+		result, err = game.RunWithLive(ctx, input, options.liveConfig)
 	} else {
-		g, err = game.Run(ctx, input)
+		result, err = game.Run(ctx, input)
 	}
 
 	if err != nil {
 		return err
 	}
 
-	if !options.noPGN {
-		fmt.Printf("PGN:%s\n", g.String())
+	white, black := path.Base(input.White.Engine), path.Base(input.Black.Engine)
+	score := game.Score{}
+	score.Add(result.Game.Outcome(), true)
+	game.PrintProgress(console(options), 1, white, black, result.Game, white, black, score)
+
+	if err := savePGN(options, result); err != nil {
+		return err
+	}
+
+	return writeGame(options, input, result)
+}
+
+// console returns the writer the console output is printed to,
+// so that it does not interleave with the JSON records.
+func console(options options) io.Writer {
+	if options.output == jsonFormat {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// savePGN appends a game to the PGN file if finished.
+func savePGN(options options, result *game.Result) error {
+	if options.pgnOut == "" || result.Game.Outcome() == chess.NoOutcome {
+		return nil
+	}
+	return game.AppendPGN(options.pgnOut, result)
+}
+
+// writeGame outputs a game in the output format.
+func writeGame(options options, input game.Input, result *game.Result) error {
+	switch {
+	case options.output == jsonFormat:
+		return game.WriteJSON(os.Stdout, input, result)
+	case options.pgnOut == "" && !options.noPGN:
+		fmt.Println("PGN:")
+		return game.WritePGN(os.Stdout, result)
 	}
 
 	return nil
 }
 
+// player returns the game player from the yaml player
+func (p yamlPlayer) player() game.Player {
+	return game.Player{
+		Engine:  p.Engine,
+		Args:    p.Args,
+		Workdir: p.Workdir,
+		Env:     p.Env,
+		Options: p.Options,
+		Ponder:  p.Ponder,
+	}
+}
+
 // parseYAML parses and validates the yaml file input
 func parseYAML(filename string) (*yamlInput, error) {
 	input := &yamlInput{}
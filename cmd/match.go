@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fatih/structs"
+	"github.com/leonhfr/cete/pkg/game"
+	"github.com/leonhfr/cete/pkg/live"
+	"github.com/leonhfr/cete/pkg/match"
+	"github.com/notnil/chess"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+type yamlMatch struct {
+	Players  []yamlPlayer `yaml:"players"`
+	Games    int          `yaml:"games"`
+	Time     int          `yaml:"time"`
+	Openings string       `yaml:"openings" structs:"-"`
+	Seed     int64        `yaml:"seed" structs:"-"`
+	PGNOut   string       `yaml:"pgn_out" structs:"-"`
+}
+
+const (
+	concurrency = "concurrency"
+	journal     = "journal"
+	resume      = "resume"
+)
+
+// matchCmd represents the match command
+var matchCmd = &cobra.Command{
+	Use:   "match [yaml file]",
+	Short: "play a match using a yaml template file",
+	Long: `The match command plays a series of games in which
+every player meets every other player, alternating colors.
+
+Each finished game is recorded in a journal file, so that an
+interrupted match can be resumed with the --resume flag.
+
+Several games can be played at once with the --concurrency flag,
+all of them being broadcast to the live view.`,
+	Args: cobra.MatchAll(cobra.MaximumNArgs(1)),
+	Example: `  cete match ./match.yaml --journal match.journal
+  cete match --resume match.journal
+  cete match ./match.yaml --concurrency 4 --broadcast`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options, err := getOptions(cmd)
+		if err != nil {
+			return err
+		}
+
+		concurrency, _ := cmd.Flags().GetInt(concurrency)
+		if concurrency < 1 {
+			return errors.New("concurrency must be at least 1")
+		}
+
+		var j *match.Journal
+		if filename, _ := cmd.Flags().GetString(resume); filename != "" {
+			if len(args) > 0 {
+				return errors.New("a resumed match is read from its journal, no yaml file expected")
+			}
+
+			j, err = match.Open(filename)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(console(options), "Resuming match after %d of %d games\n", len(j.Games), len(j.Schedule))
+		} else {
+			if len(args) == 0 {
+				return errors.New("expected a yaml file or a journal to resume")
+			}
+
+			input, err := parseMatchYAML(args[0])
+			if err != nil {
+				return err
+			}
+
+			m, err := input.match()
+			if err != nil {
+				return err
+			}
+
+			filename, _ := cmd.Flags().GetString(journal)
+			j, err = match.Create(filename, m)
+			if errors.Is(err, os.ErrExist) {
+				return fmt.Errorf("journal %s already exists, use --resume %s to resume its match", filename, filename)
+			} else if err != nil {
+				return err
+			}
+		}
+		defer j.Close()
+
+		// the PGN file of the match is restored on resume, unless overridden
+		if options.pgnOut == "" {
+			options.pgnOut = j.Match.PGNOut
+		}
+
+		return runMatch(cmd.Context(), j, options, concurrency)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(matchCmd)
+
+	matchCmd.Flags().Int(concurrency, 1, "number of games played at once")
+	matchCmd.Flags().String(journal, "cete.journal", "file the match and its finished games are recorded in")
+	matchCmd.Flags().String(resume, "", "journal of an interrupted match to resume")
+	_ = matchCmd.MarkFlagFilename(journal)
+	_ = matchCmd.MarkFlagFilename(resume)
+}
+
+// runMatch plays the pending games of a match, concurrency games at once
+func runMatch(ctx context.Context, j *match.Journal, options options, concurrency int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m := &matchRunner{
+		journal: j,
+		names:   j.Match.Names(),
+		id:      time.Now().Format("20060102-150405"),
+		options: options,
+	}
+
+	if options.broadcast {
+		view, errc, err := live.New(options.liveConfig, log.New(console(options), "cete: ", 0))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = view.Shutdown() }()
+
+		go func() {
+			if err := <-errc; err != nil && !errors.Is(err, http.ErrServerClosed) {
+				m.fail(err)
+				cancel()
+			}
+		}()
+
+		if err := view.SetStandings(j.Standings()); err != nil {
+			return err
+		}
+
+		view.Wait(ctx)
+		m.view = view
+	}
+
+	pending := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range pending {
+				if err := m.play(ctx, n); err != nil {
+					m.fail(err)
+					cancel()
+				}
+			}
+		}()
+	}
+
+schedule:
+	for _, n := range j.Pending() {
+		select {
+		case pending <- n:
+		case <-ctx.Done():
+			break schedule
+		}
+	}
+	close(pending)
+	wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	if m.interrupted || ctx.Err() != nil {
+		fmt.Fprintf(os.Stderr, "Match interrupted after %d of %d games\n", len(j.Games), len(j.Schedule))
+	}
+
+	return nil
+}
+
+// matchRunner plays the games of a match.
+//
+// Its mutex serializes the recording and the output of the finished games
+// of the concurrent games.
+type matchRunner struct {
+	journal *match.Journal
+	names   []string
+	// id prefixes the ID of each game, so that the engine logs
+	// of a resumed match do not mix with the previous ones.
+	id      string
+	options options
+	view    *live.View
+
+	mu          sync.Mutex
+	err         error
+	interrupted bool
+}
+
+// play plays a game of the schedule, records it in the journal and writes it
+func (m *matchRunner) play(ctx context.Context, n int) error {
+	pairing := m.journal.Schedule[n]
+	input := m.journal.Match.Input(pairing)
+	input.ID = fmt.Sprintf("%s-%d", m.id, n+1)
+	input.LogDir = m.options.logDir
+	input.Verbosity = m.options.verbosity
+	input.Console = console(m.options)
+
+	var result *game.Result
+	var err error
+	if m.view != nil {
+		result, err = game.Broadcast(ctx, input, m.view)
+	} else {
+		result, err = game.Run(ctx, input)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if ctx.Err() != nil || (err == nil && result.Game.Outcome() == chess.NoOutcome) {
+		m.interrupted = true
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	result.Round = n + 1
+
+	// the game is saved before it is journaled so that a crash in between
+	// replays it on resume instead of losing it
+	if err := savePGN(m.options, result); err != nil {
+		return err
+	}
+
+	if err := m.journal.Append(n, game.NewRecord(input, result)); err != nil {
+		return err
+	}
+
+	if m.view != nil {
+		if err := m.view.SetStandings(m.journal.Standings()); err != nil {
+			return err
+		}
+	}
+
+	first, second := pairing.White, pairing.Black
+	if first > second {
+		first, second = second, first
+	}
+
+	game.PrintProgress(console(m.options), n+1, m.names[pairing.White], m.names[pairing.Black], result.Game,
+		m.names[first], m.names[second], m.journal.Score(first, second))
+
+	return writeGame(m.options, input, result)
+}
+
+// fail records the first error of the games.
+func (m *matchRunner) fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err == nil {
+		m.err = err
+	}
+}
+
+// match returns the match from the yaml match
+func (m yamlMatch) match() (match.Match, error) {
+	config := match.Match{
+		Games:  m.Games,
+		Time:   time.Duration(m.Time * 10e5),
+		Seed:   m.Seed,
+		PGNOut: m.PGNOut,
+	}
+
+	for _, p := range m.Players {
+		config.Players = append(config.Players, p.player())
+	}
+
+	if m.Openings != "" {
+		f, err := os.Open(m.Openings)
+		if err != nil {
+			return config, err
+		}
+		defer f.Close()
+
+		if config.Openings, err = match.ParseOpenings(f); err != nil {
+			return config, fmt.Errorf("%s: %w", m.Openings, err)
+		}
+	}
+
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+
+	return config, nil
+}
+
+// parseMatchYAML parses and validates the yaml file match
+func parseMatchYAML(filename string) (*yamlMatch, error) {
+	input := &yamlMatch{}
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return input, err
+	}
+
+	err = yaml.Unmarshal(contents, input)
+	if err != nil {
+		return input, err
+	}
+
+	if invalid := structs.HasZero(input); invalid {
+		return input, errors.New("yaml file is missing some inputs")
+	}
+
+	if len(input.Players) < 2 {
+		return input, errors.New("yaml file must have at least two players")
+	}
+
+	for _, p := range input.Players {
+		if p.Engine == "" {
+			return input, errors.New("yaml file is missing the engine of a player")
+		}
+	}
+
+	return input, nil
+}
@@ -3,26 +3,60 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"time"
 
 	"github.com/leonhfr/cete/pkg/game"
+	"github.com/leonhfr/cete/pkg/live"
 	"github.com/spf13/cobra"
 )
 
 // options represents the global options
 type options struct {
-	broadcast bool
-	noPGN     bool
-	port      int
+	broadcast  bool
+	liveConfig live.Config
+	logDir     string
+	noPGN      bool
+	output     string
+	pgnOut     string
+	verbosity  game.Verbosity
 }
 
 const (
-	black     = "black"
-	broadcast = "broadcast"
-	noPGN     = "no-pgn"
-	port      = "port"
-	white     = "white"
+	black              = "black"
+	blackPonder        = "black-ponder"
+	broadcast          = "broadcast"
+	broadcastAddress   = "broadcast-address"
+	broadcastAutostart = "broadcast-autostart"
+	broadcastDelay     = "broadcast-delay"
+	broadcastPassword  = "broadcast-password"
+	broadcastPrivate   = "broadcast-private"
+	broadcastTLSCert   = "broadcast-tls-cert"
+	broadcastTLSKey    = "broadcast-tls-key"
+	broadcastToken     = "broadcast-token"
+	broadcastUser      = "broadcast-user"
+	logDir             = "log-dir"
+	noPGN              = "no-pgn"
+	output             = "output"
+	pgnOut             = "pgn-out"
+	port               = "port"
+	verbosity          = "verbosity"
+	white              = "white"
+	whitePonder        = "white-ponder"
+)
+
+// environment variables the secrets of the live view can be passed in,
+// instead of flags visible in the process list
+const (
+	broadcastPasswordEnv = "CETE_BROADCAST_PASSWORD"
+	broadcastTokenEnv    = "CETE_BROADCAST_TOKEN"
+)
+
+// output formats
+const (
+	pgnFormat  = "pgn"
+	jsonFormat = "json"
 )
 
 var version = "0.0.0"
@@ -40,10 +74,15 @@ A cete is a group of honey badgers.`,
 	Version:           version,
 	CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		options, err := getOptions(cmd)
+		if err != nil {
+			return err
+		}
+
 		return runGame(
 			cmd.Context(),
 			getInput(cmd),
-			getOptions(cmd),
+			options,
 		)
 	},
 }
@@ -59,12 +98,31 @@ func Execute(ctx context.Context) {
 func init() {
 	// Persistent flags
 	rootCmd.PersistentFlags().BoolP(broadcast, "b", false, "live broadcast in a web view")
+	rootCmd.PersistentFlags().Bool(broadcastAutostart, false, "start the games without waiting for the start button of the live view")
+	rootCmd.PersistentFlags().Duration(broadcastDelay, 0, "delay before the games start automatically, e.g. 30s, implies --broadcast-autostart")
+	rootCmd.PersistentFlags().String(broadcastAddress, "", "host the live view listens on, all interfaces if empty")
+	rootCmd.PersistentFlags().String(broadcastTLSCert, "", "certificate file to serve the live view over HTTPS")
+	rootCmd.PersistentFlags().String(broadcastTLSKey, "", "key file of the certificate")
+	rootCmd.PersistentFlags().String(broadcastUser, "", "user allowed to start, pause and resume the games")
+	rootCmd.PersistentFlags().String(broadcastPassword, "", "password of the user, or "+broadcastPasswordEnv)
+	rootCmd.PersistentFlags().String(broadcastToken, "", "bearer token allowed to start, pause and resume the games, or "+broadcastTokenEnv)
+	rootCmd.PersistentFlags().Bool(broadcastPrivate, false, "require the credentials to spectate as well")
+	rootCmd.PersistentFlags().String(logDir, "", "directory where engine transcripts and errors are logged")
 	rootCmd.PersistentFlags().Bool(noPGN, false, "do not print game in PGN format")
+	rootCmd.PersistentFlags().StringP(output, "o", pgnFormat, "game output: pgn or json, one object per line")
+	rootCmd.PersistentFlags().String(pgnOut, "", "PGN file each finished game is appended to, instead of printing it")
 	rootCmd.PersistentFlags().IntP(port, "p", 6061, "port used for lived broadcast")
+	rootCmd.PersistentFlags().String(verbosity, game.Transcript.String(), "console output: transcript, moves or progress")
+	_ = rootCmd.MarkPersistentFlagDirname(logDir)
+	_ = rootCmd.MarkPersistentFlagFilename(broadcastTLSCert)
+	_ = rootCmd.MarkPersistentFlagFilename(broadcastTLSKey)
+	_ = rootCmd.MarkPersistentFlagFilename(pgnOut, "pgn")
 
 	// Local flags
 	rootCmd.Flags().String(white, "stockfish", "path or command to the white engine")
 	rootCmd.Flags().String(black, "stockfish", "path or command to the black engine")
+	rootCmd.Flags().Bool(whitePonder, false, "let the white engine ponder on the opponent's time")
+	rootCmd.Flags().Bool(blackPonder, false, "let the black engine ponder on the opponent's time")
 	_ = rootCmd.MarkFlagFilename(white)
 	_ = rootCmd.MarkFlagFilename(black)
 }
@@ -73,23 +131,79 @@ func init() {
 func getInput(cmd *cobra.Command) game.Input {
 	white, _ := cmd.Flags().GetString(white)
 	black, _ := cmd.Flags().GetString(black)
+	whitePonder, _ := cmd.Flags().GetBool(whitePonder)
+	blackPonder, _ := cmd.Flags().GetBool(blackPonder)
 
 	return game.Input{
-		WhiteEngine: white,
-		BlackEngine: black,
-		Time:        500 * time.Millisecond,
+		White: game.Player{Engine: white, Ponder: whitePonder},
+		Black: game.Player{Engine: black, Ponder: blackPonder},
+		Time:  500 * time.Millisecond,
 	}
 }
 
 // getOptions returns the options from the root command persistent flags
-func getOptions(cmd *cobra.Command) options {
+func getOptions(cmd *cobra.Command) (options, error) {
 	broadcast, _ := cmd.Flags().GetBool(broadcast)
+	autostart, _ := cmd.Flags().GetBool(broadcastAutostart)
+	delay, _ := cmd.Flags().GetDuration(broadcastDelay)
+	address, _ := cmd.Flags().GetString(broadcastAddress)
+	tlsCert, _ := cmd.Flags().GetString(broadcastTLSCert)
+	tlsKey, _ := cmd.Flags().GetString(broadcastTLSKey)
+	user, _ := cmd.Flags().GetString(broadcastUser)
+	password, _ := cmd.Flags().GetString(broadcastPassword)
+	token, _ := cmd.Flags().GetString(broadcastToken)
+	private, _ := cmd.Flags().GetBool(broadcastPrivate)
+	logDir, _ := cmd.Flags().GetString(logDir)
 	noPGN, _ := cmd.Flags().GetBool(noPGN)
+	output, _ := cmd.Flags().GetString(output)
+	pgnOut, _ := cmd.Flags().GetString(pgnOut)
 	port, _ := cmd.Flags().GetInt(port)
+	v, _ := cmd.Flags().GetString(verbosity)
+
+	verbosity, err := game.ParseVerbosity(v)
+	if err != nil {
+		return options{}, err
+	}
+
+	if output != pgnFormat && output != jsonFormat {
+		return options{}, fmt.Errorf("invalid output %q, expected %s or %s", output, pgnFormat, jsonFormat)
+	}
+
+	if delay < 0 {
+		return options{}, fmt.Errorf("invalid broadcast delay %s", delay)
+	}
+
+	// the delay is only waited for when the games start automatically
+	if delay > 0 {
+		autostart = true
+	}
 
 	return options{
 		broadcast: broadcast,
+		liveConfig: live.Config{
+			Address:    address,
+			Port:       port,
+			TLSCert:    tlsCert,
+			TLSKey:     tlsKey,
+			User:       user,
+			Password:   env(password, broadcastPasswordEnv),
+			Token:      env(token, broadcastTokenEnv),
+			Private:    private,
+			Autostart:  autostart,
+			StartDelay: delay,
+		},
+		logDir:    logDir,
 		noPGN:     noPGN,
-		port:      port,
+		output:    output,
+		pgnOut:    pgnOut,
+		verbosity: verbosity,
+	}, nil
+}
+
+// env returns the value, or the environment variable if it is empty
+func env(value, key string) string {
+	if value == "" {
+		return os.Getenv(key)
 	}
+	return value
 }
@@ -1,7 +1,6 @@
 package uci
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"strings"
@@ -41,25 +40,20 @@ var (
 	CmdUCI = cmdNoOptions{Name: "uci", F: func(e *Engine) error {
 		e.id = map[string]string{}
 		e.options = map[string]Option{}
-		scanner := bufio.NewScanner(e.out)
-		for scanner.Scan() {
-			text := e.readLine(scanner)
-			k, v, err := parseIDLine(text)
-			if err == nil {
-				e.id[k] = v
-				continue
-			}
-			o := &Option{}
-			err = o.UnmarshalText([]byte(text))
-			if err == nil {
-				e.options[o.Name] = *o
-				continue
+		for {
+			ev, err := e.next()
+			if err != nil {
+				return err
 			}
-			if text == "uciok" {
-				break
+			switch ev := ev.(type) {
+			case idEvent:
+				e.id[ev.key] = ev.value
+			case optionEvent:
+				e.options[ev.option.Name] = ev.option
+			case uciOKEvent:
+				return nil
 			}
 		}
-		return nil
 	}}
 
 	// CmdIsReady corresponds to the "isready" command:
@@ -73,14 +67,15 @@ var (
 	// This command must always be answered with "readyok" and can be sent also when the engine is calculating
 	// in which case the engine should also immediately answer with "readyok" without stopping the search.
 	CmdIsReady = cmdNoOptions{Name: "isready", F: func(e *Engine) error {
-		scanner := bufio.NewScanner(e.out)
-		for scanner.Scan() {
-			text := e.readLine(scanner)
-			if text == "readyok" {
-				break
+		for {
+			ev, err := e.next()
+			if err != nil {
+				return err
+			}
+			if _, ok := ev.(readyOKEvent); ok {
+				return nil
 			}
 		}
-		return nil
 	}}
 
 	// CmdUCINewGame corresponds to the "ucinewgame" command:
@@ -136,6 +131,9 @@ type CmdSetOption struct {
 }
 
 func (cmd CmdSetOption) String() string {
+	if cmd.Value == "" {
+		return "setoption name " + cmd.Name
+	}
 	return fmt.Sprintf("setoption name %s value %s", cmd.Name, cmd.Value)
 }
 
@@ -147,7 +145,8 @@ func (cmd CmdSetOption) ProcessResponse(e *Engine) error {
 // CmdPosition corresponds to the "position" command:
 // set up the position described in fenstring on the internal board and
 // play the moves on the internal chess board.
-// if the game was played  from the start position the string "startpos" will be sent
+// if the game was played  from the start position the string "startpos" will be sent,
+// which is also the case when no position is given.
 // Note: no "new" command is needed. However, if this position is from a different game than
 // the last position sent to the engine, the GUI should have sent a "ucinewgame" inbetween.
 type CmdPosition struct {
@@ -156,23 +155,27 @@ type CmdPosition struct {
 }
 
 func (cmd CmdPosition) String() string {
-	if cmd.Position == nil {
-		cmd.Position = chess.StartingPosition()
+	position := "startpos"
+	if cmd.Position != nil && cmd.Position.String() != chess.StartingPosition().String() {
+		position = "fen " + cmd.Position.String()
 	}
 	if len(cmd.Moves) == 0 {
-		return "position fen " + cmd.Position.String()
+		return "position " + position
 	}
 	moveStrs := []string{}
 	for _, m := range cmd.Moves {
 		mStr := chess.UCINotation{}.Encode(nil, m)
 		moveStrs = append(moveStrs, mStr)
 	}
-	return fmt.Sprintf("position fen %s moves %s", cmd.Position, strings.Join(moveStrs, " "))
+	return fmt.Sprintf("position %s moves %s", position, strings.Join(moveStrs, " "))
 }
 
 // ProcessResponse implements the Cmd interface
 func (cmd CmdPosition) ProcessResponse(e *Engine) error {
 	e.position = cmd.Position
+	if e.position == nil {
+		e.position = chess.StartingPosition()
+	}
 	for _, move := range cmd.Moves {
 		e.position = e.position.Update(move)
 	}
@@ -281,38 +284,58 @@ func (cmd CmdGo) String() string {
 
 // ProcessResponse implements the Cmd interface
 func (CmdGo) ProcessResponse(e *Engine) error {
-	scanner := bufio.NewScanner(e.out)
 	results := SearchResults{}
-	for scanner.Scan() {
-		text := e.readLine(scanner)
-		if strings.HasPrefix(text, "bestmove") {
-			parts := strings.Split(text, " ")
-			if len(parts) <= 1 {
-				return errors.New("best move not found " + text)
+	lines := map[int]Info{}
+	for {
+		ev, err := e.next()
+		if err != nil {
+			return err
+		}
+		switch ev := ev.(type) {
+		case infoEvent:
+			info := &Info{}
+			if err := info.unmarshal(e.position, ev.text); err == nil {
+				results.Info = *info
+				if len(info.PV) > 0 {
+					// engines may omit multipv when not in multi pv mode
+					index := info.Multipv
+					if index == 0 {
+						index = 1
+					}
+					lines[index] = *info
+				}
+				e.info(*info)
+			}
+		case bestMoveEvent:
+			if ev.move == "(none)" || ev.move == "0000" {
+				// no legal move, e.g. a stopped ponder search on a position
+				// where the predicted reply mates or stalemates the engine
+				results.MultiPV = multiPV(lines)
+				e.results = results
+				return nil
 			}
-			bestMove, err := chess.UCINotation{}.Decode(e.position, parts[1])
+			bestMove, err := chess.UCINotation{}.Decode(e.position, ev.move)
 			if err != nil {
 				return err
 			}
 			results.BestMove = bestMove
-			if len(parts) >= 4 {
-				ponderMove, err := chess.UCINotation{}.Decode(e.position, parts[3])
+			if ev.ponder != "" {
+				// the ponder move is played after the best move
+				pos := e.position
+				if pos != nil {
+					pos = pos.Update(bestMove)
+				}
+				ponderMove, err := chess.UCINotation{}.Decode(pos, ev.ponder)
 				if err != nil {
 					return err
 				}
 				results.Ponder = ponderMove
 			}
-			break
-		}
-
-		info := &Info{}
-		err := info.UnmarshalText([]byte(text))
-		if err == nil {
-			results.Info = *info
+			results.MultiPV = multiPV(lines)
+			e.results = results
+			return nil
 		}
 	}
-	e.results = results
-	return nil
 }
 
 func parseIDLine(s string) (string, string, error) {
@@ -0,0 +1,43 @@
+package uci
+
+import (
+	"testing"
+
+	"github.com/notnil/chess"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmdPositionString(t *testing.T) {
+	fen := "r4r2/1b2bppk/ppq1p3/2pp3n/5P2/1P2P3/PBPPQ1PP/R4RK1 w - - 0 2"
+	fn, _ := chess.FEN(fen)
+	pos := chess.NewGame(fn).Position()
+
+	tests := []struct {
+		name string
+		args CmdPosition
+		want string
+	}{
+		{"no position", CmdPosition{}, "position startpos"},
+		{"starting position", CmdPosition{Position: chess.StartingPosition()}, "position startpos"},
+		{"starting position with moves", CmdPosition{Moves: moves("e2e4", "e7e5", "g1f3")}, "position startpos moves e2e4 e7e5 g1f3"},
+		{"fen", CmdPosition{Position: pos}, "position fen " + fen},
+		{"fen with moves", CmdPosition{Position: pos, Moves: moves("b2h8", "h7h8")}, "position fen " + fen + " moves b2h8 h7h8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.args.String())
+		})
+	}
+}
+
+func TestCmdPositionProcessResponse(t *testing.T) {
+	e := &Engine{}
+	game := chess.NewGame()
+	for _, m := range []string{"e4", "e5", "Nf3"} {
+		_ = game.MoveStr(m)
+	}
+
+	assert.NoError(t, CmdPosition{Moves: game.Moves()}.ProcessResponse(e))
+	assert.Equal(t, game.Position().String(), e.position.String())
+}
@@ -1,12 +1,13 @@
 package uci
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sync"
 	"syscall"
 
@@ -17,14 +18,19 @@ import (
 // Engine is safe for concurrent use.
 type Engine struct {
 	cmd      *exec.Cmd
-	in       *io.PipeWriter
-	out      *io.PipeReader
+	in       io.WriteCloser
+	out      io.ReadCloser
+	events   chan event
+	done     chan struct{}
 	debug    bool
 	logger   *log.Logger
 	id       map[string]string
 	options  map[string]Option
 	results  SearchResults
+	infoFn   func(Info)
 	mu       *sync.RWMutex
+	wmu      *sync.Mutex
+	imu      *sync.Mutex
 	position *chess.Position
 }
 
@@ -42,27 +48,93 @@ func Logger(logger *log.Logger) func(e *Engine) {
 	}
 }
 
+// Args is an option for the New function to pass command-line arguments to the engine.
+func Args(args ...string) func(e *Engine) {
+	return func(e *Engine) {
+		e.cmd.Args = append(e.cmd.Args[:1], args...)
+	}
+}
+
+// Dir is an option for the New function to set the working directory of the engine.
+// New fails if the directory does not exist.
+func Dir(dir string) func(e *Engine) {
+	return func(e *Engine) {
+		e.cmd.Dir = dir
+	}
+}
+
+// Env is an option for the New function to add environment variables, in the form
+// "key=value", to the environment inherited by the engine.
+func Env(env ...string) func(e *Engine) {
+	return func(e *Engine) {
+		e.cmd.Env = append(os.Environ(), env...)
+	}
+}
+
+// Stderr is an option for the New function to write the engine's standard error.
+// The standard error is discarded otherwise.
+func Stderr(w io.Writer) func(e *Engine) {
+	return func(e *Engine) {
+		e.cmd.Stderr = w
+	}
+}
+
 // New constructs an engine from the executable path (found using exec.LookPath).
-// New also starts running the executable process in the background.  Once created
-// the Engine can be controlled via the Run method.
+// New also starts running the executable process in the background, along with a
+// single goroutine reading and parsing its output.  Once created the Engine can be
+// controlled via the Run method.  An error is returned if the process cannot be
+// started, e.g. if its working directory does not exist.
 func New(path string, opts ...func(e *Engine)) (*Engine, error) {
 	path, err := exec.LookPath(path)
 	if err != nil {
 		return nil, fmt.Errorf("uci: executable not found at path %s %w", path, err)
 	}
-	rIn, wIn := io.Pipe()
-	rOut, wOut := io.Pipe()
+	// relative paths would otherwise be resolved from the working directory option
+	if path, err = filepath.Abs(path); err != nil {
+		return nil, err
+	}
 	cmd := exec.Command(path)
-	cmd.Stdin = rIn
-	cmd.Stdout = wOut
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		Setpgid: true,
 	}
-	e := &Engine{cmd: cmd, in: wIn, out: rOut, mu: &sync.RWMutex{}, logger: log.New(os.Stdout, "uci", log.LstdFlags)}
+	e := &Engine{
+		cmd:    cmd,
+		events: make(chan event, eventBuffer),
+		done:   make(chan struct{}),
+		mu:     &sync.RWMutex{},
+		wmu:    &sync.Mutex{},
+		imu:    &sync.Mutex{},
+		logger: log.New(os.Stdout, "uci", log.LstdFlags),
+	}
 	for _, opt := range opts {
 		opt(e)
 	}
-	go e.cmd.Run()
+	// the process fails to start with a misleading error otherwise
+	if cmd.Dir != "" {
+		info, err := os.Stat(cmd.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("uci: working directory %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("uci: working directory %s is not a directory", cmd.Dir)
+		}
+	}
+	// the pipes are OS pipes so that the process exiting closes the output
+	// and fails the writes to its input, instead of blocking them
+	if e.in, err = cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	if e.out, err = cmd.StdoutPipe(); err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("uci: %w", err)
+	}
+	go func() {
+		e.read()
+		// the pipes are closed once the process has exited and its output is read
+		_ = cmd.Wait()
+	}()
 	return e, nil
 }
 
@@ -127,16 +199,38 @@ func (e *Engine) SearchResults() SearchResults {
 	return e.results
 }
 
+// OnInfo registers a function called with every info line the engine sends
+// during subsequent CmdGo invocations, as soon as it is received.  This allows
+// following the search as the engine thinks instead of waiting for the
+// SearchResults.  Passing nil removes the function.  It can be called while
+// a search is running, e.g. to follow a ponder search once resolved.
+func (e *Engine) OnInfo(fn func(Info)) {
+	e.imu.Lock()
+	defer e.imu.Unlock()
+	e.infoFn = fn
+}
+
+// info calls the registered info function, if any.
+func (e *Engine) info(info Info) {
+	e.imu.Lock()
+	defer e.imu.Unlock()
+	if e.infoFn != nil {
+		e.infoFn(info)
+	}
+}
+
 // Run runs the set of Cmds in the order given and returns an error if
-// any of the commands fails.  Except for CmdStop (usually paired with
-// CmdGo's infinite option) all commands block via mutux until completed.
+// any of the commands fails.  Except for CmdStop, CmdPonderHit and CmdQuit,
+// which control a running search (see Go), all commands block via mutex
+// until completed.
 func (e *Engine) Run(cmds ...Cmd) error {
 	for _, cmd := range cmds {
-		if cmd.String() == CmdStop.Name {
+		switch cmd.String() {
+		case CmdStop.Name, CmdPonderHit.Name, CmdQuit.Name:
 			if err := e.processCommand(cmd); err != nil {
 				return err
 			}
-		} else {
+		default:
 			if err := e.processCommandLocked(cmd); err != nil {
 				return err
 			}
@@ -145,19 +239,41 @@ func (e *Engine) Run(cmds ...Cmd) error {
 	return nil
 }
 
+// Go starts the search and returns as soon as the CmdGo is sent, without
+// waiting for the engine's best move.  The returned channel receives the
+// result of the search once the engine sends its best move, after which
+// SearchResults are available.  Meanwhile, CmdStop and CmdPonderHit can be
+// run to control the search, e.g. to resolve a ponder search.
+func (e *Engine) Go(cmd CmdGo) <-chan error {
+	done := make(chan error, 1)
+	e.mu.Lock()
+	if err := e.send(cmd); err != nil {
+		e.mu.Unlock()
+		done <- err
+		return done
+	}
+	go func() {
+		defer e.mu.Unlock()
+		done <- cmd.ProcessResponse(e)
+	}()
+	return done
+}
+
 // Close releases readers, writers, and processes associated with the
 // Engine.  It also invokes the CmdQuit to signal the engine to terminate.
 func (e *Engine) Close() error {
-	if err := e.Run(CmdQuit); err != nil {
-		return err
-	}
-	if err := e.in.Close(); err != nil {
+	// the engine may have exited already, in which case it cannot be told to quit
+	err := e.Run(CmdQuit)
+	_ = e.in.Close()
+	close(e.done)
+	_ = e.out.Close()
+	if e.cmd == nil {
 		return err
 	}
-	if err := e.out.Close(); err != nil {
-		return err
+	if kerr := e.cmd.Process.Kill(); kerr != nil && !errors.Is(kerr, os.ErrProcessDone) {
+		return kerr
 	}
-	return e.cmd.Process.Kill()
+	return err
 }
 
 func (e *Engine) processCommandLocked(cmd Cmd) error {
@@ -167,10 +283,7 @@ func (e *Engine) processCommandLocked(cmd Cmd) error {
 }
 
 func (e *Engine) processCommand(cmd Cmd) error {
-	if e.debug {
-		e.logger.Println(cmd.String())
-	}
-	if _, err := fmt.Fprintln(e.in, cmd.String()); err != nil {
+	if err := e.send(cmd); err != nil {
 		return err
 	}
 	if err := cmd.ProcessResponse(e); err != nil {
@@ -179,10 +292,14 @@ func (e *Engine) processCommand(cmd Cmd) error {
 	return nil
 }
 
-func (e *Engine) readLine(scanner *bufio.Scanner) string {
-	s := scanner.Text()
+// send writes the command to the engine.  Writes are serialized so that
+// commands controlling a search can be sent while it is running.
+func (e *Engine) send(cmd Cmd) error {
+	e.wmu.Lock()
+	defer e.wmu.Unlock()
 	if e.debug {
-		e.logger.Println(s)
+		e.logger.Println(cmd.String())
 	}
-	return s
+	_, err := fmt.Fprintln(e.in, cmd.String())
+	return err
 }
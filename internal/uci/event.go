@@ -0,0 +1,135 @@
+package uci
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+)
+
+// eventBuffer is the number of events the reader goroutine can queue
+// before it blocks waiting for a command to consume them.
+const eventBuffer = 256
+
+// errOutputClosed is returned when the engine output is closed while a
+// command is still waiting for a response (e.g. the engine crashed).
+var errOutputClosed = errors.New("uci: engine output closed")
+
+// event is a line of engine output parsed by the reader goroutine.
+// Moves are kept in UCI notation since decoding them requires the
+// position the engine is searching.
+type event interface {
+	isEvent()
+}
+
+// idEvent corresponds to the "id" engine output:
+// id name Stockfish 14.1
+type idEvent struct {
+	key   string
+	value string
+}
+
+// optionEvent corresponds to the "option" engine output:
+// option name Hash type spin default 16 min 1 max 33554432
+type optionEvent struct {
+	option Option
+}
+
+// uciOKEvent corresponds to the "uciok" engine output.
+type uciOKEvent struct{}
+
+// readyOKEvent corresponds to the "readyok" engine output.
+type readyOKEvent struct{}
+
+// infoEvent corresponds to the "info" engine output:
+// info depth 12 seldepth 14 multipv 1 score cp 50 nodes 55039 nps 534359 tbhits 0 time 103 pv e2e4
+type infoEvent struct {
+	text string
+}
+
+// bestMoveEvent corresponds to the "bestmove" engine output:
+// bestmove e2e4 ponder c7c5
+type bestMoveEvent struct {
+	move   string
+	ponder string
+}
+
+// unknownEvent is any line of engine output that is not part of the protocol,
+// such as the banner most engines print on startup.
+type unknownEvent struct {
+	text string
+}
+
+func (idEvent) isEvent()       {}
+func (optionEvent) isEvent()   {}
+func (uciOKEvent) isEvent()    {}
+func (readyOKEvent) isEvent()  {}
+func (infoEvent) isEvent()     {}
+func (bestMoveEvent) isEvent() {}
+func (unknownEvent) isEvent()  {}
+
+// parseEvent parses a single line of engine output.
+func parseEvent(text string) event {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return unknownEvent{text}
+	}
+
+	switch fields[0] {
+	case "id":
+		if k, v, err := parseIDLine(text); err == nil {
+			return idEvent{k, v}
+		}
+	case "option":
+		o := Option{}
+		if err := o.UnmarshalText([]byte(text)); err == nil {
+			return optionEvent{o}
+		}
+	case "uciok":
+		return uciOKEvent{}
+	case "readyok":
+		return readyOKEvent{}
+	case "info":
+		return infoEvent{text}
+	case "bestmove":
+		if len(fields) < 2 {
+			break
+		}
+		ev := bestMoveEvent{move: fields[1]}
+		if len(fields) >= 4 && fields[2] == "ponder" {
+			ev.ponder = fields[3]
+		}
+		return ev
+	}
+
+	return unknownEvent{text}
+}
+
+// read is the single reader of the engine output. It parses every line
+// into an event and delivers it on the events channel until the output
+// is closed or the engine is closed.
+func (e *Engine) read() {
+	defer close(e.events)
+
+	scanner := bufio.NewScanner(e.out)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if e.debug {
+			e.logger.Println(text)
+		}
+
+		select {
+		case e.events <- parseEvent(text):
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// next blocks until the engine sends the next event.
+func (e *Engine) next() (event, error) {
+	ev, ok := <-e.events
+	if !ok {
+		return nil, errOutputClosed
+	}
+	return ev, nil
+}
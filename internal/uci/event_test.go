@@ -0,0 +1,237 @@
+package uci
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want event
+	}{
+		{"empty", "", unknownEvent{""}},
+		{"banner", "Stockfish 14.1 by the Stockfish developers (see AUTHORS file)", unknownEvent{"Stockfish 14.1 by the Stockfish developers (see AUTHORS file)"}},
+		{"id", "id name Stockfish 14.1", idEvent{"name", "Stockfish 14.1"}},
+		{"invalid id", "id", unknownEvent{"id"}},
+		{"option", "option name Hash type spin default 16 min 1 max 33554432", optionEvent{Option{Name: "Hash", Type: OptionSpin, Default: "16", Min: "1", Max: "33554432"}}},
+		{"invalid option", "option name Hash", unknownEvent{"option name Hash"}},
+		{"uciok", "uciok", uciOKEvent{}},
+		{"readyok", "readyok", readyOKEvent{}},
+		{"info", "info depth 1 seldepth 1 multipv 1 score cp 38 pv d2d4", infoEvent{"info depth 1 seldepth 1 multipv 1 score cp 38 pv d2d4"}},
+		{"bestmove", "bestmove e2e4", bestMoveEvent{move: "e2e4"}},
+		{"bestmove ponder", "bestmove e2e4 ponder c7c5", bestMoveEvent{move: "e2e4", ponder: "c7c5"}},
+		{"invalid bestmove", "bestmove", unknownEvent{"bestmove"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseEvent(tt.args))
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	e, w := newTestEngine()
+	defer close(e.done)
+
+	go fmt.Fprintln(w, `Stockfish 14.1 by the Stockfish developers (see AUTHORS file)
+id name Stockfish 14.1
+option name Hash type spin default 16 min 1 max 33554432
+uciok
+readyok
+info depth 1 seldepth 1 multipv 1 score cp 38 nodes 20 nps 20000 tbhits 0 time 1 pv d2d4
+info depth 2 seldepth 2 multipv 1 score cp 82 nodes 51 nps 51000 tbhits 0 time 1 pv e2e4 a7a6
+bestmove e2e4 ponder a7a6`)
+
+	assert.NoError(t, CmdUCI.ProcessResponse(e))
+	assert.Equal(t, map[string]string{"name": "Stockfish 14.1"}, e.ID())
+	assert.Contains(t, e.Options(), "Hash")
+
+	assert.NoError(t, CmdIsReady.ProcessResponse(e))
+
+	assert.NoError(t, CmdGo{}.ProcessResponse(e))
+	assert.Equal(t, "e2e4", e.SearchResults().BestMove.String())
+	assert.Equal(t, "a7a6", e.SearchResults().Ponder.String())
+	assert.Equal(t, 2, e.SearchResults().Info.Depth)
+}
+
+func TestReadClosed(t *testing.T) {
+	e, w := newTestEngine()
+	defer close(e.done)
+
+	go func() {
+		fmt.Fprintln(w, "info depth 1 seldepth 1 multipv 1 score cp 38")
+		w.Close()
+	}()
+
+	assert.ErrorIs(t, CmdGo{}.ProcessResponse(e), errOutputClosed)
+}
+
+func TestEngineExited(t *testing.T) {
+	e, err := New("sh", Args("-c", "echo boom; exit 1"))
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() { done <- e.Run(CmdUCI) }()
+
+	select {
+	case err := <-done:
+		// the command fails writing to the engine or reading its output
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the command to fail once the engine exited")
+	}
+
+	closed := make(chan error)
+	go func() { closed <- e.Close() }()
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the engine to close once exited")
+	}
+}
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		opts []func(*Engine)
+	}{
+		{"executable not found", "cete-no-such-engine", nil},
+		{"working directory not found", "true", []func(*Engine){Dir("/nonexistent/dir")}},
+		{"working directory not a directory", "true", []func(*Engine){Dir("/dev/null")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.path, tt.opts...)
+			assert.Error(t, err)
+			assert.Nil(t, e)
+		})
+	}
+}
+
+func TestEngineBadArgs(t *testing.T) {
+	// engines rejecting their arguments exit before identifying themselves
+	e, err := New("sh", Args("-c", "echo unknown flag >&2; exit 2", "--bad-flag"))
+	require.NoError(t, err)
+	defer e.Close()
+
+	done := make(chan error)
+	go func() { done <- e.Run(CmdUCI) }()
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the command to fail once the engine exited")
+	}
+}
+
+// newTestEngine returns an engine reading its output from the returned writer.
+// The commands sent to the engine are discarded.
+func newTestEngine() (*Engine, *io.PipeWriter) {
+	rIn, wIn := io.Pipe()
+	go func() { _, _ = io.Copy(io.Discard, rIn) }()
+	r, w := io.Pipe()
+	e := &Engine{
+		in:     wIn,
+		out:    r,
+		events: make(chan event, eventBuffer),
+		done:   make(chan struct{}),
+		mu:     &sync.RWMutex{},
+		wmu:    &sync.Mutex{},
+		imu:    &sync.Mutex{},
+		logger: log.New(io.Discard, "", 0),
+	}
+	go e.read()
+	return e, w
+}
+
+func TestOnInfo(t *testing.T) {
+	e, w := newTestEngine()
+	defer close(e.done)
+
+	go fmt.Fprintln(w, `info depth 1 seldepth 1 multipv 1 score cp 38 nodes 20 nps 20000 tbhits 0 time 1 pv d2d4
+info depth 2 seldepth 2 multipv 1 score cp 82 nodes 51 nps 51000 tbhits 0 time 1 pv e2e4 a7a6
+bestmove e2e4 ponder a7a6`)
+
+	var depths []int
+	e.OnInfo(func(info Info) {
+		depths = append(depths, info.Depth)
+	})
+
+	assert.NoError(t, CmdGo{}.ProcessResponse(e))
+	assert.Equal(t, []int{1, 2}, depths)
+}
+
+func TestMultiPV(t *testing.T) {
+	e, w := newTestEngine()
+	defer close(e.done)
+
+	go fmt.Fprintln(w, `info depth 1 seldepth 1 multipv 1 score cp 38 nodes 20 nps 20000 tbhits 0 time 1 pv d2d4
+info depth 1 seldepth 1 multipv 2 score cp 30 nodes 20 nps 20000 tbhits 0 time 1 pv e2e4
+info depth 2 seldepth 2 multipv 1 score cp 82 nodes 51 nps 51000 tbhits 0 time 1 pv e2e4 a7a6
+info depth 2 seldepth 2 multipv 2 score cp 40 nodes 51 nps 51000 tbhits 0 time 1 pv d2d4 d7d5
+info depth 3 currmove g1f3 currmovenumber 3
+bestmove e2e4 ponder a7a6`)
+
+	assert.NoError(t, CmdGo{}.ProcessResponse(e))
+
+	results := e.SearchResults()
+	assert.Equal(t, 3, results.Info.Depth)
+	assert.Len(t, results.MultiPV, 2)
+	for i, info := range results.MultiPV {
+		assert.Equal(t, i+1, info.Multipv)
+		assert.Equal(t, 2, info.Depth)
+	}
+	assert.Equal(t, 82, results.MultiPV[0].Score.CP)
+	assert.Equal(t, 40, results.MultiPV[1].Score.CP)
+}
+
+func TestGo(t *testing.T) {
+	e, w := newTestEngine()
+	defer close(e.done)
+
+	done := e.Go(CmdGo{Ponder: true})
+	select {
+	case <-done:
+		t.Fatal("expected the search to run in the background")
+	default:
+	}
+
+	// commands controlling the search do not wait for it
+	assert.NoError(t, e.Run(CmdPonderHit))
+
+	go fmt.Fprintln(w, `info depth 1 seldepth 1 multipv 1 score cp 38 pv d2d4
+bestmove d2d4`)
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, "d2d4", e.SearchResults().BestMove.String())
+}
+
+func TestGoNoMove(t *testing.T) {
+	e, w := newTestEngine()
+	defer close(e.done)
+
+	// the predicted reply mates the engine, so the stopped ponder search has no move
+	done := e.Go(CmdGo{Ponder: true})
+	assert.NoError(t, e.Run(CmdStop))
+
+	go fmt.Fprintln(w, `info depth 0 score mate 0
+bestmove (none)`)
+
+	assert.NoError(t, <-done)
+	assert.Nil(t, e.SearchResults().BestMove)
+	assert.Nil(t, e.SearchResults().Ponder)
+}
@@ -0,0 +1,44 @@
+package uci
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+)
+
+// NewFake constructs an engine backed by a scripted fake instead of a process,
+// e.g. to test code driving an engine.  The respond function is called with
+// every command sent to the engine and returns the lines the engine outputs
+// in response, if any.
+func NewFake(respond func(cmd string) []string) *Engine {
+	rIn, wIn := io.Pipe()
+	rOut, wOut := io.Pipe()
+	e := &Engine{
+		in:     wIn,
+		out:    rOut,
+		events: make(chan event, eventBuffer),
+		done:   make(chan struct{}),
+		mu:     &sync.RWMutex{},
+		wmu:    &sync.Mutex{},
+		imu:    &sync.Mutex{},
+		logger: log.New(io.Discard, "", 0),
+	}
+
+	go func() {
+		// closing the input makes the fake exit
+		defer wOut.Close()
+		scanner := bufio.NewScanner(rIn)
+		for scanner.Scan() {
+			for _, line := range respond(scanner.Text()) {
+				if _, err := fmt.Fprintln(wOut, line); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	go e.read()
+	return e
+}
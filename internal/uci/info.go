@@ -2,6 +2,7 @@ package uci
 
 import (
 	"errors"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -13,10 +14,15 @@ import (
 // data such as the following:
 // info depth 21 seldepth 31 multipv 1 score cp 39 nodes 862438 nps 860716 hashfull 409 tbhits 0 time 1002 pv e2e4
 // bestmove e2e4 ponder c7c5
+//
+// Info is the last info line sent by the engine.  MultiPV holds the latest info
+// line of each principal variation, ordered by multipv index, such that with
+// MultiPV > 1 all the best lines found are available.
 type SearchResults struct {
 	BestMove *chess.Move
 	Ponder   *chess.Move
 	Info     Info
+	MultiPV  []Info
 }
 
 // Info corresponds to the "info" engine output:
@@ -83,20 +89,43 @@ type SearchResults struct {
 //    if the engine is just using one cpu,  can be omitted.
 //    If  is greater than 1, always send all k lines in k strings together.
 // 	The engine should only send this if the option "UCI_ShowCurrLine" is set to true.
+// * wdl
+// 	win, draw and loss probabilities in permill from the engine's point of view.
+// 	This is an extension sent along the score by engines with the option "UCI_ShowWDL" set to true.
 type Info struct {
 	Depth             int
 	Seldepth          int
 	PV                []*chess.Move
 	Multipv           int
 	Time              time.Duration
-	Nodes             int
+	Nodes             int64
 	Score             Score
+	WDL               WDL
 	CurrentMove       *chess.Move
 	CurrentMoveNumber int
 	Hashfull          int
-	NPS               int
-	TBHits            int
+	NPS               int64
+	TBHits            int64
 	CPULoad           int
+	String            string
+	Refutation        []*chess.Move
+	CurrentLine       []*chess.Move
+	CurrentLineCPU    int
+}
+
+// multiPV returns the info lines ordered by their multipv index.
+func multiPV(lines map[int]Info) []Info {
+	indexes := make([]int, 0, len(lines))
+	for i := range lines {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	infos := make([]Info, 0, len(indexes))
+	for _, i := range indexes {
+		infos = append(infos, lines[i])
+	}
+	return infos
 }
 
 // Score corresponds to the "info"'s score engine output:
@@ -117,123 +146,163 @@ type Score struct {
 	UpperBound bool
 }
 
+// WDL corresponds to the "info"'s wdl engine output:
+// * wdl
+// 	win, draw and loss probabilities in permill from the engine's point of view.
+type WDL struct {
+	Win  int
+	Draw int
+	Loss int
+}
+
 // UnmarshalText implements the encoding.TextUnmarshaler interface and parses
 // data like the following:
 // info depth 24 seldepth 32 multipv 1 score cp 29 nodes 5130101 nps 819897 hashfull 967 tbhits 0 time 6257 pv d2d4
+//
+// Moves are decoded without a position, so they lack tags such as captures or castling.
 func (info *Info) UnmarshalText(text []byte) error {
-	parts := strings.Split(string(text), " ")
-	if len(parts) == 0 {
-		return errors.New("uci: invalid info line " + string(text))
-	}
-	if parts[0] != "info" {
-		return errors.New("uci: invalid info line " + string(text))
+	return info.unmarshal(nil, string(text))
+}
+
+// infoItems maps the keywords of an info line to their number of values.
+// Items with a negative number of values last up to the next keyword.
+var infoItems = map[string]int{
+	"depth": 1, "seldepth": 1, "time": 1, "nodes": 1, "pv": -1, "multipv": 1,
+	"score": 0, "cp": 1, "mate": 1, "lowerbound": 0, "upperbound": 0, "wdl": 3,
+	"currmove": 1, "currmovenumber": 1, "hashfull": 1, "nps": 1, "tbhits": 1,
+	"sbhits": 1, "cpuload": 1, "string": -1, "refutation": -1, "currline": -1,
+}
+
+// unmarshal parses an info line, decoding moves against the searched position.
+// Unknown tokens are skipped.
+func (info *Info) unmarshal(pos *chess.Position, text string) error {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || fields[0] != "info" {
+		return errors.New("uci: invalid info line " + text)
 	}
-	ref := ""
-	for i := 1; i < len(parts); i++ {
-		s := parts[i]
-		switch s {
-		case "score":
-			continue
-		case "lowerbound":
-			info.Score.LowerBound = true
-			continue
-		case "upperbound":
-			info.Score.UpperBound = true
+
+	for i := 1; i < len(fields); {
+		key := fields[i]
+		n, ok := infoItems[key]
+		switch {
+		case !ok:
+			i++
 			continue
+		case key == "string":
+			// the rest of the line is the string
+			info.String = strings.Join(fields[i+1:], " ")
+			return nil
+		case n < 0:
+			n = 0
+			for i+1+n < len(fields) {
+				if _, ok := infoItems[fields[i+1+n]]; ok {
+					break
+				}
+				n++
+			}
+		case i+1+n > len(fields):
+			return errors.New("uci: invalid info line " + text)
 		}
-		if ref == "" {
-			ref = s
-			continue
+
+		if err := info.set(pos, key, fields[i+1:i+1+n]); err != nil {
+			return errors.New("uci: invalid info line " + text + ": " + err.Error())
 		}
-		switch ref {
-		case "depth":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.Depth = v
-		case "seldepth":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.Seldepth = v
-		case "multipv":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.Multipv = v
-		case "cp":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.Score.CP = v
-		case "nodes":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.Nodes = v
-		case "mate":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.Score.Mate = v
-		case "currmovenumber":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.CurrentMoveNumber = v
-		case "currmove":
-			m, err := chess.UCINotation{}.Decode(nil, s)
-			if err != nil {
-				return err
-			}
-			info.CurrentMove = m
-		case "hashfull":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.Hashfull = v
-		case "tbhits":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.TBHits = v
-		case "time":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.Time = time.Millisecond * time.Duration(v)
-		case "nps":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.NPS = v
-		case "cpuload":
-			v, err := strconv.Atoi(s)
-			if err != nil {
-				return err
-			}
-			info.CPULoad = v
-		case "pv":
-			m, err := chess.UCINotation{}.Decode(nil, s)
-			if err != nil {
-				return err
+		i += 1 + n
+	}
+
+	return nil
+}
+
+// set sets the info item from its values.
+func (info *Info) set(pos *chess.Position, key string, values []string) error {
+	var err error
+	switch key {
+	case "depth":
+		info.Depth, err = parseInt(values)
+	case "seldepth":
+		info.Seldepth, err = parseInt(values)
+	case "multipv":
+		info.Multipv, err = parseInt(values)
+	case "cp":
+		info.Score.CP, err = parseInt(values)
+	case "mate":
+		info.Score.Mate, err = parseInt(values)
+	case "lowerbound":
+		info.Score.LowerBound = true
+	case "upperbound":
+		info.Score.UpperBound = true
+	case "wdl":
+		if info.WDL.Win, err = parseInt(values[0:1]); err != nil {
+			return err
+		}
+		if info.WDL.Draw, err = parseInt(values[1:2]); err != nil {
+			return err
+		}
+		info.WDL.Loss, err = parseInt(values[2:3])
+	case "nodes":
+		info.Nodes, err = parseInt64(values)
+	case "nps":
+		info.NPS, err = parseInt64(values)
+	case "tbhits":
+		info.TBHits, err = parseInt64(values)
+	case "currmovenumber":
+		info.CurrentMoveNumber, err = parseInt(values)
+	case "hashfull":
+		info.Hashfull, err = parseInt(values)
+	case "cpuload":
+		info.CPULoad, err = parseInt(values)
+	case "time":
+		var v int
+		v, err = parseInt(values)
+		info.Time = time.Millisecond * time.Duration(v)
+	case "currmove":
+		info.CurrentMove, err = chess.UCINotation{}.Decode(pos, values[0])
+	case "pv":
+		info.PV, err = decodeMoves(pos, values)
+	case "refutation":
+		info.Refutation, err = decodeMoves(pos, values)
+	case "currline":
+		// the cpu number is omitted when the engine uses a single cpu
+		if len(values) > 0 {
+			if cpu, err := strconv.Atoi(values[0]); err == nil {
+				info.CurrentLineCPU = cpu
+				values = values[1:]
 			}
-			info.PV = append(info.PV, m)
 		}
-		if ref != "pv" {
-			ref = ""
+		info.CurrentLine, err = decodeMoves(pos, values)
+	}
+	return err
+}
+
+// parseInt parses a single integer value.
+func parseInt(values []string) (int, error) {
+	if len(values) != 1 {
+		return 0, errors.New("expected a single value")
+	}
+	return strconv.Atoi(values[0])
+}
+
+// parseInt64 parses a single 64-bit integer value.
+func parseInt64(values []string) (int64, error) {
+	if len(values) != 1 {
+		return 0, errors.New("expected a single value")
+	}
+	return strconv.ParseInt(values[0], 10, 64)
+}
+
+// decodeMoves decodes a sequence of moves played from the position.
+// If the position is nil, moves are decoded without a position.
+func decodeMoves(pos *chess.Position, values []string) ([]*chess.Move, error) {
+	moves := make([]*chess.Move, 0, len(values))
+	for _, s := range values {
+		m, err := chess.UCINotation{}.Decode(pos, s)
+		if err != nil {
+			return nil, err
+		}
+		moves = append(moves, m)
+		if pos != nil {
+			pos = pos.Update(m)
 		}
 	}
-	return nil
+	return moves, nil
 }
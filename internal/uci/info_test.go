@@ -0,0 +1,120 @@
+package uci
+
+import (
+	"testing"
+	"time"
+
+	"github.com/notnil/chess"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfoUnmarshal(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want Info
+	}{
+		{
+			"search",
+			"info depth 21 seldepth 31 multipv 1 score cp 39 nodes 862438 nps 860716 hashfull 409 tbhits 0 time 1002 pv e2e4 e7e5",
+			Info{
+				Depth: 21, Seldepth: 31, Multipv: 1, Score: Score{CP: 39}, Nodes: 862438, NPS: 860716,
+				Hashfull: 409, Time: 1002 * time.Millisecond, PV: moves("e2e4", "e7e5"),
+			},
+		},
+		{
+			"nodes above 32 bits",
+			"info depth 40 nodes 12345678901234 nps 98765432109 tbhits 4294967296",
+			Info{Depth: 40, Nodes: 12345678901234, NPS: 98765432109, TBHits: 4294967296},
+		},
+		{
+			"mate bound",
+			"info depth 30 score mate -3 upperbound pv e2e4",
+			Info{Depth: 30, Score: Score{Mate: -3, UpperBound: true}, PV: moves("e2e4")},
+		},
+		{
+			"wdl",
+			"info depth 18 seldepth 24 multipv 1 score cp 35 wdl 85 904 11 nodes 1000 pv d2d4",
+			Info{Depth: 18, Seldepth: 24, Multipv: 1, Score: Score{CP: 35}, WDL: WDL{85, 904, 11}, Nodes: 1000, PV: moves("d2d4")},
+		},
+		{
+			"string",
+			"info string NNUE evaluation using nn-13406b1dcbe0.nnue enabled",
+			Info{String: "NNUE evaluation using nn-13406b1dcbe0.nnue enabled"},
+		},
+		{
+			"string after items",
+			"info depth 3 string depth is not a keyword here",
+			Info{Depth: 3, String: "depth is not a keyword here"},
+		},
+		{
+			"current move",
+			"info depth 12 currmove g1f3 currmovenumber 3",
+			Info{Depth: 12, CurrentMove: moves("g1f3")[0], CurrentMoveNumber: 3},
+		},
+		{
+			"refutation",
+			"info refutation d1h5 g6h5",
+			Info{Refutation: moves("d1h5", "g6h5")},
+		},
+		{
+			"current line with cpu",
+			"info currline 2 e2e4 e7e5",
+			Info{CurrentLineCPU: 2, CurrentLine: moves("e2e4", "e7e5")},
+		},
+		{
+			"current line without cpu",
+			"info currline e2e4 e7e5",
+			Info{CurrentLine: moves("e2e4", "e7e5")},
+		},
+		{
+			"unknown items",
+			"info depth 10 ebf 1.85 nodes 100 foo bar baz time 12",
+			Info{Depth: 10, Nodes: 100, Time: 12 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := Info{}
+			assert.NoError(t, info.UnmarshalText([]byte(tt.args)))
+			assert.Equal(t, tt.want, info)
+		})
+	}
+}
+
+func TestInfoUnmarshalError(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"bestmove e2e4",
+		"info depth",
+		"info depth x",
+		"info wdl 1 2",
+		"info pv e2",
+	} {
+		t.Run(text, func(t *testing.T) {
+			info := Info{}
+			assert.Error(t, info.UnmarshalText([]byte(text)))
+		})
+	}
+}
+
+func TestInfoUnmarshalPosition(t *testing.T) {
+	fen, _ := chess.FEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	pos := chess.NewGame(fen).Position()
+
+	info := Info{}
+	err := info.unmarshal(pos, "info depth 2 currmove e1g1 pv e1g1 e8c8 refutation e1c1 e8g8")
+	assert.NoError(t, err)
+	assert.True(t, info.CurrentMove.HasTag(chess.KingSideCastle))
+	assert.True(t, info.PV[0].HasTag(chess.KingSideCastle))
+	assert.True(t, info.PV[1].HasTag(chess.QueenSideCastle))
+	assert.True(t, info.Refutation[0].HasTag(chess.QueenSideCastle))
+	assert.True(t, info.Refutation[1].HasTag(chess.KingSideCastle))
+}
+
+// moves decodes moves without a position.
+func moves(s ...string) []*chess.Move {
+	m, _ := decodeMoves(nil, s)
+	return m
+}
@@ -116,48 +116,76 @@ type Option struct {
 // UnmarshalText implements the encoding.TextUnmarshaler interface and parses
 // data like the following:
 // option name EvalFile type string default nn-82215d0fd0df.nnue
+//
+// Names, defaults and vars may contain spaces: the name lasts up to the type,
+// string defaults last up to the end of the line and other values up to the
+// next keyword.  The "<empty>" default is parsed as the empty string.
+//
+// The line is split on single spaces so that values keep their inner spacing,
+// e.g. paths in string defaults, while the spaces around them are trimmed.
 func (o *Option) UnmarshalText(text []byte) error {
-	o.Type = OptionNoType
-	parts := strings.Split(string(text), " ")
-	if len(parts) == 0 {
+	*o = Option{Type: OptionNoType}
+	line := strings.TrimSpace(string(text))
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "option" || fields[1] != "name" {
 		return errors.New("uci: invalid option line")
 	}
-	if parts[0] != "option" {
-		return errors.New("uci: invalid option line")
-	}
-	ref := ""
-	for i := 1; i < len(parts); i++ {
-		s := parts[i]
-		if ref == "" {
-			ref = s
+
+	_, rest, _ := strings.Cut(line, "name")
+	tokens := strings.Split(rest, " ")
+	key, values := "name", []string{}
+	for i := 0; i <= len(tokens); i++ {
+		if i < len(tokens) && !o.isKeyword(key, tokens[i]) {
+			values = append(values, tokens[i])
 			continue
 		}
-		switch ref {
+
+		value := strings.Trim(strings.Join(values, " "), " ")
+		switch key {
 		case "name":
-			o.Name = s
+			o.Name = value
 		case "type":
-			ot, err := optionTypeFromString(s)
+			ot, err := optionTypeFromString(value)
 			if err != nil {
 				return err
 			}
 			o.Type = ot
 		case "default":
-			o.Default = s
+			if value == "<empty>" {
+				value = ""
+			}
+			o.Default = value
 		case "min":
-			o.Min = s
+			o.Min = value
 		case "max":
-			o.Max = s
+			o.Max = value
 		case "var":
-			o.Vars = append(o.Vars, s)
+			o.Vars = append(o.Vars, value)
+		}
+		if i < len(tokens) {
+			key, values = tokens[i], []string{}
 		}
-		ref = ""
 	}
+
 	if o.Name == "" || o.Type == OptionNoType {
 		return errors.New("uci: invalid option line")
 	}
 	return nil
 }
 
+// isKeyword reports whether the token ends the value of the current key
+// and starts a new one.
+func (o *Option) isKeyword(key, s string) bool {
+	switch {
+	case key == "name":
+		return s == "type"
+	case key == "default" && o.Type == OptionString:
+		return false
+	default:
+		return s == "type" || s == "default" || s == "min" || s == "max" || s == "var"
+	}
+}
+
 // OptionType corresponds to the "option"'s type engine output:
 // * type
 // The option has type t.
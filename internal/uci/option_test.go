@@ -0,0 +1,143 @@
+package uci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionUnmarshalText(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want Option
+	}{
+		// Stockfish 14.1
+		{
+			"stockfish string without default",
+			"option name Debug Log File type string default",
+			Option{Name: "Debug Log File", Type: OptionString},
+		},
+		{
+			"stockfish spin",
+			"option name Hash type spin default 16 min 1 max 33554432",
+			Option{Name: "Hash", Type: OptionSpin, Default: "16", Min: "1", Max: "33554432"},
+		},
+		{
+			"stockfish button",
+			"option name Clear Hash type button",
+			Option{Name: "Clear Hash", Type: OptionButton},
+		},
+		{
+			"stockfish check",
+			"option name Ponder type check default false",
+			Option{Name: "Ponder", Type: OptionCheck, Default: "false"},
+		},
+		{
+			"stockfish multi-word spin",
+			"option name Skill Level type spin default 20 min 0 max 20",
+			Option{Name: "Skill Level", Type: OptionSpin, Default: "20", Min: "0", Max: "20"},
+		},
+		{
+			"stockfish empty string",
+			"option name SyzygyPath type string default <empty>",
+			Option{Name: "SyzygyPath", Type: OptionString},
+		},
+		{
+			"stockfish multi-word check",
+			"option name Use NNUE type check default true",
+			Option{Name: "Use NNUE", Type: OptionCheck, Default: "true"},
+		},
+		// Stockfish 12
+		{
+			"stockfish combo",
+			"option name Analysis Contempt type combo default Both var Off var White var Black var Both",
+			Option{Name: "Analysis Contempt", Type: OptionCombo, Default: "Both", Vars: []string{"Off", "White", "Black", "Both"}},
+		},
+		{
+			"stockfish negative min",
+			"option name Contempt type spin default 24 min -100 max 100",
+			Option{Name: "Contempt", Type: OptionSpin, Default: "24", Min: "-100", Max: "100"},
+		},
+		// Lc0 0.28
+		{
+			"lc0 string placeholder",
+			"option name WeightsFile type string default <autodiscover>",
+			Option{Name: "WeightsFile", Type: OptionString, Default: "<autodiscover>"},
+		},
+		{
+			"lc0 combo",
+			"option name Backend type combo default cuda-auto var cuda-auto var cuda var cuda-fp16 var blas var random",
+			Option{Name: "Backend", Type: OptionCombo, Default: "cuda-auto", Vars: []string{"cuda-auto", "cuda", "cuda-fp16", "blas", "random"}},
+		},
+		// Komodo 13
+		{
+			"komodo combo",
+			"option name Personality type combo default Default var Default var Aggressive var Defensive var Active var Positional var Endgame",
+			Option{Name: "Personality", Type: OptionCombo, Default: "Default", Vars: []string{"Default", "Aggressive", "Defensive", "Active", "Positional", "Endgame"}},
+		},
+		// Arasan 23
+		{
+			"arasan multi-word names",
+			"option name Position Learning type check default true",
+			Option{Name: "Position Learning", Type: OptionCheck, Default: "true"},
+		},
+		// synthetic edge cases, not captured from an engine
+		{
+			"multi-word combo vars",
+			"option name Style type combo default Very Solid var Very Solid var Normal var Very Risky",
+			Option{Name: "Style", Type: OptionCombo, Default: "Very Solid", Vars: []string{"Very Solid", "Normal", "Very Risky"}},
+		},
+		{
+			"string with keywords",
+			"option name Book File type string default C:\\Program Files\\Engine var book.bin",
+			Option{Name: "Book File", Type: OptionString, Default: "C:\\Program Files\\Engine var book.bin"},
+		},
+		{
+			"string with repeated spaces",
+			"option name Book File type string default /books/my  book.bin",
+			Option{Name: "Book File", Type: OptionString, Default: "/books/my  book.bin"},
+		},
+		{
+			"combo vars with repeated spaces",
+			"option name Style type combo default Very  Solid var Very  Solid var Normal",
+			Option{Name: "Style", Type: OptionCombo, Default: "Very  Solid", Vars: []string{"Very  Solid", "Normal"}},
+		},
+		// UCI specification
+		{
+			"spec path",
+			"option name NalimovPath type string default c:\\",
+			Option{Name: "NalimovPath", Type: OptionString, Default: "c:\\"},
+		},
+		{
+			"spec extra spaces",
+			"option  name  Selectivity  type spin  default 2 min 0 max 4",
+			Option{Name: "Selectivity", Type: OptionSpin, Default: "2", Min: "0", Max: "4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := Option{}
+			assert.NoError(t, o.UnmarshalText([]byte(tt.args)))
+			assert.Equal(t, tt.want, o)
+		})
+	}
+}
+
+func TestOptionUnmarshalTextError(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"option",
+		"option type spin",
+		"option name Hash",
+		"option name type spin",
+		"option name Hash type slider default 16",
+		"id name Stockfish 14.1",
+	} {
+		t.Run(text, func(t *testing.T) {
+			o := Option{}
+			assert.Error(t, o.UnmarshalText([]byte(text)))
+		})
+	}
+}
@@ -3,9 +3,9 @@ package engine
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"math"
-	"os"
 	"path"
 	"strings"
 	"time"
@@ -15,41 +15,164 @@ import (
 	"github.com/notnil/chess"
 )
 
+// Config is the configuration of an engine process.
+type Config struct {
+	// Path is the path or command to the engine.
+	Path string
+	// Args are the command-line arguments passed to the engine.
+	Args []string
+	// Dir is the working directory of the engine, the current directory if empty.
+	Dir string
+	// Env are variables added to the engine environment, in the form "key=value".
+	Env []string
+	// Ponder enables the Ponder option of the engine, if it advertises it.
+	Ponder bool
+}
+
+// options returns the options starting the engine process.
+func (c Config) options() []func(e *uci.Engine) {
+	opts := []func(e *uci.Engine){uci.Args(c.Args...)}
+	if c.Dir != "" {
+		opts = append(opts, uci.Dir(c.Dir))
+	}
+	if len(c.Env) > 0 {
+		opts = append(opts, uci.Env(c.Env...))
+	}
+	return opts
+}
+
 // Start starts a UCI engine and sets it up to run searches.
-func Start(exec string, len int, color chess.Color, options map[string]string) (*uci.Engine, error) {
-	name := path.Base(exec)
-	ew := newEngineWriter(name, len, color)
-	logFn := uci.Logger(log.New(ew, "", 0))
+func Start(config Config, options map[string]string, transcript Transcript) (*uci.Engine, error) {
+	name := path.Base(config.Path)
+	opts := config.options()
 
-	e, err := uci.New(exec, logFn)
+	tw, stderr := transcript.writers(name)
+	if transcript.Console != nil || transcript.Log != nil {
+		opts = append(opts, uci.Debug, uci.Logger(log.New(tw, "", 0)))
+	}
+	if stderr != nil {
+		opts = append(opts, uci.Stderr(stderr))
+	}
+
+	e, err := uci.New(config.Path, opts...)
 	if err != nil {
 		return nil, err
 	}
-	uci.Debug(e)
 
-	commands := []uci.Cmd{uci.CmdUCI}
-	for name, value := range options {
-		commands = append(commands, uci.CmdSetOption{Name: name, Value: value})
+	if err = e.Run(uci.CmdUCI); err != nil {
+		e.Close()
+		return nil, err
+	}
+
+	if config.Ponder {
+		options = ponderOptions(e.Options(), options)
+	}
+
+	commands, err := validateOptions(name, e.Options(), options)
+	if err != nil {
+		e.Close()
+		return nil, err
 	}
 	commands = append(commands, uci.CmdIsReady, uci.CmdUCINewGame)
 
 	if err = e.Run(commands...); err != nil {
-		return e, err
+		e.Close()
+		return nil, err
 	}
 
 	return e, nil
 }
 
-// Search runs a single search.
-func Search(e *uci.Engine, p *chess.Position, moveTime time.Duration) (*chess.Move, error) {
+// Inspect starts a UCI engine, returns its id and the options it exposes
+// and shuts it down.
+func Inspect(config Config) (map[string]string, map[string]uci.Option, error) {
+	e, err := uci.New(config.Path, config.options()...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer e.Close()
+
+	if err := e.Run(uci.CmdUCI); err != nil {
+		return nil, nil, err
+	}
+
+	return e.ID(), e.Options(), nil
+}
+
+// Search runs a single search on the game's current position.
+//
+// The position is sent as the game's starting position and its moves
+// so that the engine knows the game history, e.g. to avoid repetitions.
+//
+// If onInfo is not nil, it is called with every info line sent by the engine
+// during the search.
+func Search(e *uci.Engine, game *chess.Game, moveTime time.Duration, onInfo func(uci.Info)) (uci.SearchResults, error) {
+	e.OnInfo(onInfo)
+	defer e.OnInfo(nil)
+
 	err := e.Run(
-		uci.CmdPosition{Position: p},
+		uci.CmdPosition{Position: game.Positions()[0], Moves: game.Moves()},
 		uci.CmdGo{MoveTime: moveTime},
 	)
+	if err != nil {
+		return uci.SearchResults{}, err
+	}
+	return e.SearchResults(), nil
+}
+
+// Ponder starts a ponder search on the game's position after the expected move
+// and returns as soon as the engine is pondering.  The returned channel receives
+// the result of the search, which must be resolved with PonderHit or StopPonder.
+func Ponder(e *uci.Engine, game *chess.Game, move *chess.Move) (<-chan error, error) {
+	moves := append(game.Moves(), move)
+	err := e.Run(uci.CmdPosition{Position: game.Positions()[0], Moves: moves})
 	if err != nil {
 		return nil, err
 	}
-	return e.SearchResults().BestMove, nil
+	return e.Go(uci.CmdGo{Ponder: true}), nil
+}
+
+// PonderHit tells a pondering engine that the expected move was played and
+// waits for its best move.
+//
+// The engine is given its full move time from the ponder hit, as the time
+// spent pondering is taken on the opponent's time.  The search is stopped
+// once the move time has elapsed.
+//
+// If onInfo is not nil, it is called with every info line sent by the engine
+// from the ponder hit.
+func PonderHit(e *uci.Engine, pondering <-chan error, moveTime time.Duration, onInfo func(uci.Info)) (uci.SearchResults, error) {
+	e.OnInfo(onInfo)
+	defer e.OnInfo(nil)
+
+	if err := e.Run(uci.CmdPonderHit); err != nil {
+		return uci.SearchResults{}, err
+	}
+
+	timer := time.NewTimer(moveTime)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case err = <-pondering:
+	case <-timer.C:
+		if err = e.Run(uci.CmdStop); err == nil {
+			err = <-pondering
+		}
+	}
+
+	if err != nil {
+		return uci.SearchResults{}, err
+	}
+	return e.SearchResults(), nil
+}
+
+// StopPonder stops a pondering engine and discards the results of the search.
+func StopPonder(e *uci.Engine, pondering <-chan error) error {
+	if err := e.Run(uci.CmdStop); err != nil {
+		return err
+	}
+	return <-pondering
 }
 
 // Close gracefully shuts down an engine.
@@ -65,46 +188,46 @@ func NameLength(exec1, exec2 string) int {
 }
 
 type engineWriter struct {
+	w    io.Writer
 	name string
 	len  int
 	ansi *ansi.Color
 }
 
-func newEngineWriter(name string, len int, c chess.Color) engineWriter {
+func newEngineWriter(w io.Writer, name string, len int, c chess.Color) engineWriter {
 	switch c { //nolint
 	case chess.White:
-		return engineWriter{name, len, ansi.New(ansi.FgHiBlack, ansi.BgHiWhite)}
+		return engineWriter{w, name, len, ansi.New(ansi.FgHiBlack, ansi.BgHiWhite)}
 	case chess.Black:
-		return engineWriter{name, len, ansi.New(ansi.FgHiWhite, ansi.BgHiBlack)}
+		return engineWriter{w, name, len, ansi.New(ansi.FgHiWhite, ansi.BgHiBlack)}
 	default:
 		panic("expected valid color")
 	}
 }
 
 // Write implements the io.Writer interface.
-func (ew engineWriter) Write(p []byte) (n int, err error) {
+func (ew engineWriter) Write(p []byte) (int, error) {
 	prefix := fmt.Sprintf("%*s:", ew.len-len(ew.name), ew.name)
-	n, err = ew.ansi.Fprint(os.Stdout, prefix)
-	if err != nil {
-		return
+	if _, err := ew.ansi.Fprint(ew.w, prefix); err != nil {
+		return 0, err
 	}
 
-	m, err := fmt.Fprintf(os.Stdout, " %s %s", arrow(string(p)), string(p))
-	n += m
+	if _, err := fmt.Fprintf(ew.w, " %s %s", arrow(string(p)), string(p)); err != nil {
+		return 0, err
+	}
 
-	return
+	return len(p), nil
 }
 
 func arrow(message string) string {
-	for _, prefix := range guiToEnginePrefixes {
-		if strings.HasPrefix(message, prefix) {
-			return "-->"
-		}
+	fields := strings.Fields(message)
+	if len(fields) > 0 && guiToEngineCommands[fields[0]] {
+		return "-->"
 	}
 	return "<--"
 }
 
-var guiToEnginePrefixes = []string{
-	"uci", "debug", "isready", "setoption", "ucinewgame",
-	"position", "go", "stop", "quit",
+var guiToEngineCommands = map[string]bool{
+	"uci": true, "debug": true, "isready": true, "setoption": true, "ucinewgame": true,
+	"position": true, "go": true, "stop": true, "ponderhit": true, "quit": true,
 }
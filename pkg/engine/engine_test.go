@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/notnil/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPonderHit(t *testing.T) {
+	e, commands := fakeEngine(map[string][]string{
+		"ponderhit": {"info depth 2 score cp 20 pv g1f3 b8c6", "bestmove g1f3 ponder b8c6"},
+	})
+	defer e.Close()
+
+	game := newGame(t, "e2e4")
+	pondering, err := Ponder(e, game, move(t, game, "e7e5"))
+	require.NoError(t, err)
+
+	var depths []int
+	results, err := PonderHit(e, pondering, time.Minute, func(info uci.Info) {
+		depths = append(depths, info.Depth)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "g1f3", results.BestMove.String())
+	assert.Equal(t, "b8c6", results.Ponder.String())
+	assert.Equal(t, []int{2}, depths)
+	assert.Equal(t, []string{"position startpos moves e2e4 e7e5", "go ponder", "ponderhit"}, commands())
+}
+
+func TestPonderHitTimeout(t *testing.T) {
+	// the engine keeps searching after the ponder hit until stopped
+	e, commands := fakeEngine(map[string][]string{
+		"stop": {"bestmove g1f3"},
+	})
+	defer e.Close()
+
+	game := newGame(t, "e2e4")
+	pondering, err := Ponder(e, game, move(t, game, "e7e5"))
+	require.NoError(t, err)
+
+	results, err := PonderHit(e, pondering, 10*time.Millisecond, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "g1f3", results.BestMove.String())
+	assert.Equal(t, []string{"position startpos moves e2e4 e7e5", "go ponder", "ponderhit", "stop"}, commands())
+}
+
+func TestStopPonder(t *testing.T) {
+	tests := []struct {
+		name     string
+		bestmove string
+	}{
+		{"best move", "bestmove g1f3"},
+		// the expected move mates or stalemates the engine
+		{"no move", "bestmove (none)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, commands := fakeEngine(map[string][]string{
+				"stop": {tt.bestmove},
+			})
+			defer e.Close()
+
+			game := newGame(t, "e2e4")
+			pondering, err := Ponder(e, game, move(t, game, "e7e5"))
+			require.NoError(t, err)
+
+			assert.NoError(t, StopPonder(e, pondering))
+			assert.Equal(t, []string{"position startpos moves e2e4 e7e5", "go ponder", "stop"}, commands())
+		})
+	}
+}
+
+// fakeEngine returns an engine answering the commands from the script,
+// along with a function returning the commands it received.
+func fakeEngine(script map[string][]string) (*uci.Engine, func() []string) {
+	mu := &sync.Mutex{}
+	var commands []string
+	e := uci.NewFake(func(cmd string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		if cmd != "quit" {
+			commands = append(commands, cmd)
+		}
+		return script[cmd]
+	})
+
+	return e, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, commands...)
+	}
+}
+
+// newGame returns a game from the starting position after the moves.
+func newGame(t *testing.T, moves ...string) *chess.Game {
+	game := chess.NewGame()
+	for _, m := range moves {
+		require.NoError(t, game.Move(move(t, game, m)))
+	}
+	return game
+}
+
+// move decodes a move in UCI notation on the game's current position.
+func move(t *testing.T, game *chess.Game, s string) *chess.Move {
+	m, err := chess.UCINotation{}.Decode(game.Position(), s)
+	require.NoError(t, err)
+	return m
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/leonhfr/cete/internal/uci"
+)
+
+// validateOptions validates the requested options against the options
+// advertised by the engine and returns the setoption commands to send.
+//
+// Option names are matched case-insensitively, as per the UCI protocol,
+// and are sent with the name advertised by the engine.
+func validateOptions(name string, advertised map[string]uci.Option, requested map[string]string) ([]uci.Cmd, error) {
+	byName := make(map[string]uci.Option, len(advertised))
+	valid := make([]string, 0, len(advertised))
+	for _, o := range advertised {
+		byName[strings.ToLower(o.Name)] = o
+		valid = append(valid, o.Name)
+	}
+	sort.Strings(valid)
+
+	keys := make([]string, 0, len(requested))
+	for k := range requested {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	commands := make([]uci.Cmd, 0, len(requested))
+	for _, k := range keys {
+		o, ok := byName[strings.ToLower(k)]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown option %q, valid options are: %s", name, k, strings.Join(valid, ", "))
+		}
+
+		value, err := validateOption(o, requested[k])
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid value %q for option %q: %w", name, requested[k], o.Name, err)
+		}
+
+		commands = append(commands, uci.CmdSetOption{Name: o.Name, Value: value})
+	}
+
+	return commands, nil
+}
+
+// ponderOptions returns the requested options with the Ponder option enabled,
+// unless explicitly set, as engines may adapt their time management when
+// pondering.  As per the UCI protocol, it is only enabled if advertised.
+func ponderOptions(advertised map[string]uci.Option, requested map[string]string) map[string]string {
+	ponder := ""
+	for _, o := range advertised {
+		if strings.EqualFold(o.Name, "Ponder") {
+			ponder = o.Name
+		}
+	}
+
+	options := make(map[string]string, len(requested)+1)
+	if ponder != "" {
+		options[ponder] = "true"
+	}
+	for k, v := range requested {
+		if strings.EqualFold(k, "Ponder") {
+			delete(options, ponder)
+		}
+		options[k] = v
+	}
+	return options
+}
+
+// validateOption validates a value against the option definition
+// and returns the value to send.
+func validateOption(o uci.Option, value string) (string, error) {
+	switch o.Type {
+	case uci.OptionCheck:
+		v := strings.ToLower(value)
+		if v != "true" && v != "false" {
+			return "", errors.New("expected true or false")
+		}
+		return v, nil
+	case uci.OptionSpin:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return "", errors.New("expected an integer")
+		}
+		lo, errMin := strconv.Atoi(o.Min)
+		hi, errMax := strconv.Atoi(o.Max)
+		if (errMin == nil && v < lo) || (errMax == nil && v > hi) {
+			return "", fmt.Errorf("expected a value between %s and %s", o.Min, o.Max)
+		}
+		return value, nil
+	case uci.OptionCombo:
+		for _, v := range o.Vars {
+			if strings.EqualFold(v, value) {
+				return v, nil
+			}
+		}
+		return "", fmt.Errorf("expected one of %s", strings.Join(o.Vars, ", "))
+	case uci.OptionButton:
+		if value != "" {
+			return "", errors.New("button options take no value")
+		}
+		return "", nil
+	default:
+		return value, nil
+	}
+}
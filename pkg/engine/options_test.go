@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOptions(t *testing.T) {
+	advertised := map[string]uci.Option{
+		"Hash":       {Name: "Hash", Type: uci.OptionSpin, Default: "16", Min: "1", Max: "33554432"},
+		"Clear Hash": {Name: "Clear Hash", Type: uci.OptionButton},
+		"Ponder":     {Name: "Ponder", Type: uci.OptionCheck, Default: "false"},
+		"Style":      {Name: "Style", Type: uci.OptionCombo, Default: "Normal", Vars: []string{"Solid", "Normal", "Risky"}},
+		"SyzygyPath": {Name: "SyzygyPath", Type: uci.OptionString},
+	}
+
+	tests := []struct {
+		name    string
+		args    map[string]string
+		want    []uci.Cmd
+		wantErr string
+	}{
+		{
+			"no options",
+			nil,
+			[]uci.Cmd{},
+			"",
+		},
+		{
+			"valid options",
+			map[string]string{"Hash": "32", "Ponder": "true", "Style": "Risky", "SyzygyPath": "/tb", "Clear Hash": ""},
+			[]uci.Cmd{
+				uci.CmdSetOption{Name: "Clear Hash"},
+				uci.CmdSetOption{Name: "Hash", Value: "32"},
+				uci.CmdSetOption{Name: "Ponder", Value: "true"},
+				uci.CmdSetOption{Name: "Style", Value: "Risky"},
+				uci.CmdSetOption{Name: "SyzygyPath", Value: "/tb"},
+			},
+			"",
+		},
+		{
+			"case insensitive",
+			map[string]string{"hash": "32", "ponder": "TRUE", "style": "risky"},
+			[]uci.Cmd{
+				uci.CmdSetOption{Name: "Hash", Value: "32"},
+				uci.CmdSetOption{Name: "Ponder", Value: "true"},
+				uci.CmdSetOption{Name: "Style", Value: "Risky"},
+			},
+			"",
+		},
+		{
+			"unknown option",
+			map[string]string{"Threads": "2"},
+			nil,
+			`stockfish: unknown option "Threads", valid options are: Clear Hash, Hash, Ponder, Style, SyzygyPath`,
+		},
+		{
+			"spin not an integer",
+			map[string]string{"Hash": "large"},
+			nil,
+			`stockfish: invalid value "large" for option "Hash": expected an integer`,
+		},
+		{
+			"spin out of range",
+			map[string]string{"Hash": "0"},
+			nil,
+			`stockfish: invalid value "0" for option "Hash": expected a value between 1 and 33554432`,
+		},
+		{
+			"invalid check",
+			map[string]string{"Ponder": "yes"},
+			nil,
+			`stockfish: invalid value "yes" for option "Ponder": expected true or false`,
+		},
+		{
+			"invalid combo",
+			map[string]string{"Style": "Crazy"},
+			nil,
+			`stockfish: invalid value "Crazy" for option "Style": expected one of Solid, Normal, Risky`,
+		},
+		{
+			"button with value",
+			map[string]string{"Clear Hash": "true"},
+			nil,
+			`stockfish: invalid value "true" for option "Clear Hash": button options take no value`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateOptions("stockfish", advertised, tt.args)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPonderOptions(t *testing.T) {
+	ponder := map[string]uci.Option{
+		"Hash":   {Name: "Hash", Type: uci.OptionSpin, Default: "16", Min: "1", Max: "33554432"},
+		"Ponder": {Name: "Ponder", Type: uci.OptionCheck, Default: "false"},
+	}
+	noPonder := map[string]uci.Option{
+		"Hash": {Name: "Hash", Type: uci.OptionSpin, Default: "16", Min: "1", Max: "33554432"},
+	}
+
+	tests := []struct {
+		name       string
+		advertised map[string]uci.Option
+		args       map[string]string
+		want       map[string]string
+	}{
+		{"enabled", ponder, map[string]string{"Hash": "32"}, map[string]string{"Hash": "32", "Ponder": "true"}},
+		{"explicitly set", ponder, map[string]string{"ponder": "false"}, map[string]string{"ponder": "false"}},
+		{"not advertised", noPonder, map[string]string{"Hash": "32"}, map[string]string{"Hash": "32"}},
+		{"no options", noPonder, nil, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ponderOptions(tt.advertised, tt.args))
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+// timestampLayout is the layout of the timestamps in the log files.
+const timestampLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// Transcript configures where the UCI transcript of an engine is written.
+type Transcript struct {
+	// Console receives the transcript, each line prefixed with
+	// the engine name in the color it plays. Nil means no console output.
+	Console    io.Writer
+	NameLength int
+	Color      chess.Color
+	// Log receives the timestamped transcript along with the engine's standard error.
+	Log *Log
+}
+
+// Log is a log file receiving the timestamped transcript of an engine
+// along with its standard error.
+type Log struct {
+	transcript *fileWriter
+	stderr     *fileWriter
+}
+
+// NewLog returns a log writing to w.
+func NewLog(w io.Writer) *Log {
+	mu := &sync.Mutex{}
+	return &Log{
+		transcript: &fileWriter{mu: mu, w: w},
+		stderr:     &fileWriter{mu: mu, w: w, stderr: true},
+	}
+}
+
+// Flush writes the last lines of the log not terminated by a newline,
+// e.g. the output of an engine that crashed. It is called once the engine
+// is closed, before closing the underlying writer.
+func (l *Log) Flush() error {
+	if err := l.stderr.flush(); err != nil {
+		return err
+	}
+	return l.transcript.flush()
+}
+
+// writers returns the writers of the transcript and of the engine's standard error.
+// Nil writers mean the output is discarded.
+func (t Transcript) writers(name string) (io.Writer, io.Writer) {
+	var transcript []io.Writer
+	if t.Console != nil {
+		transcript = append(transcript, newEngineWriter(t.Console, name, t.NameLength, t.Color))
+	}
+	if t.Log == nil {
+		return io.MultiWriter(transcript...), nil
+	}
+
+	transcript = append(transcript, t.Log.transcript)
+	return io.MultiWriter(transcript...), t.Log.stderr
+}
+
+// fileWriter writes timestamped lines to a log file.
+//
+// Writes are line buffered as the engine's standard error is written
+// in arbitrary chunks, and serialized since both the transcript and
+// the standard error share the same file.
+type fileWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	stderr bool
+	buf    []byte
+}
+
+// Write implements the io.Writer interface.
+func (fw *fileWriter) Write(p []byte) (int, error) {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	fw.buf = append(fw.buf, p...)
+	for {
+		i := bytes.IndexByte(fw.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+
+		line := string(fw.buf[:i])
+		fw.buf = fw.buf[i+1:]
+
+		if err := fw.writeLine(line); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// flush writes the buffered partial line, if any.
+func (fw *fileWriter) flush() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if len(fw.buf) == 0 {
+		return nil
+	}
+
+	line := string(fw.buf)
+	fw.buf = nil
+	return fw.writeLine(line)
+}
+
+// writeLine writes a timestamped line, marked with its direction.
+func (fw *fileWriter) writeLine(line string) error {
+	line = strings.TrimRight(line, "\r")
+
+	marker := arrow(line)
+	if fw.stderr {
+		marker = "!!!"
+	}
+
+	timestamp := time.Now().Format(timestampLayout)
+	_, err := fmt.Fprintf(fw.w, "%s %s %s\n", timestamp, marker, line)
+	return err
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/notnil/chess"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWriter(t *testing.T) {
+	b := &bytes.Buffer{}
+	mu := &sync.Mutex{}
+	transcript := &fileWriter{mu: mu, w: b}
+	stderr := &fileWriter{mu: mu, w: b, stderr: true}
+
+	for _, w := range []struct {
+		fw *fileWriter
+		p  string
+	}{
+		{transcript, "uci\n"},
+		{stderr, "loading net"},
+		{transcript, "id name Stockfish 14.1\n"},
+		{stderr, "work...\r\ndone\n"},
+		{transcript, "uciok\nisready\n"},
+	} {
+		n, err := w.fw.Write([]byte(w.p))
+		assert.NoError(t, err)
+		assert.Equal(t, len(w.p), n)
+	}
+
+	timestamp := regexp.MustCompile(`(?m)^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}\S+ `)
+	assert.Equal(t, `--> uci
+<-- id name Stockfish 14.1
+!!! loading network...
+!!! done
+<-- uciok
+--> isready
+`, timestamp.ReplaceAllString(b.String(), ""))
+}
+
+func TestLogFlush(t *testing.T) {
+	b := &bytes.Buffer{}
+	log := NewLog(b)
+	tw, stderr := Transcript{Log: log}.writers("stockfish")
+
+	_, err := tw.Write([]byte("uci\n"))
+	assert.NoError(t, err)
+	_, err = stderr.Write([]byte("segmentation fault"))
+	assert.NoError(t, err)
+
+	timestamp := regexp.MustCompile(`(?m)^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{6}\S+ `)
+	assert.Equal(t, "--> uci\n", timestamp.ReplaceAllString(b.String(), ""))
+
+	assert.NoError(t, log.Flush())
+	assert.Equal(t, "--> uci\n!!! segmentation fault\n", timestamp.ReplaceAllString(b.String(), ""))
+
+	// nothing is left to flush
+	assert.NoError(t, log.Flush())
+	assert.Equal(t, "--> uci\n!!! segmentation fault\n", timestamp.ReplaceAllString(b.String(), ""))
+}
+
+func TestTranscriptConsole(t *testing.T) {
+	b := &bytes.Buffer{}
+	tw, stderr := Transcript{Console: b, NameLength: 9, Color: chess.White}.writers("stockfish")
+	assert.Nil(t, stderr)
+
+	_, err := tw.Write([]byte("uci\n"))
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), "stockfish:")
+	assert.Contains(t, b.String(), " --> uci\n")
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/notnil/chess"
+)
+
+// Verbosity is the level of detail printed on the console while playing.
+type Verbosity int
+
+const (
+	// Transcript prints the full UCI transcript of both engines.
+	Transcript Verbosity = iota
+	// Moves prints a summary of each move: move, score, depth and time.
+	Moves
+	// Progress only prints a line per finished game with the running score.
+	Progress
+)
+
+var verbosityNames = map[Verbosity]string{
+	Transcript: "transcript",
+	Moves:      "moves",
+	Progress:   "progress",
+}
+
+// String implements the fmt.Stringer interface.
+func (v Verbosity) String() string {
+	return verbosityNames[v]
+}
+
+// ParseVerbosity parses a verbosity from its name.
+func ParseVerbosity(s string) (Verbosity, error) {
+	for v, name := range verbosityNames {
+		if strings.EqualFold(s, name) {
+			return v, nil
+		}
+	}
+	return Transcript, fmt.Errorf("invalid verbosity %q, expected transcript, moves or progress", s)
+}
+
+// Score is the running score of a match from the first engine's point of view.
+type Score struct {
+	Wins   int
+	Losses int
+	Draws  int
+}
+
+// Add adds the outcome of a game to the score.
+func (s *Score) Add(outcome chess.Outcome, firstIsWhite bool) {
+	switch outcome {
+	case chess.Draw:
+		s.Draws++
+	case chess.WhiteWon:
+		if firstIsWhite {
+			s.Wins++
+		} else {
+			s.Losses++
+		}
+	case chess.BlackWon:
+		if firstIsWhite {
+			s.Losses++
+		} else {
+			s.Wins++
+		}
+	case chess.NoOutcome:
+	}
+}
+
+// Games returns the number of finished games.
+func (s Score) Games() int {
+	return s.Wins + s.Losses + s.Draws
+}
+
+// String implements the fmt.Stringer interface and formats the score as
+// wins - losses - draws followed by the score ratio and the number of games.
+func (s Score) String() string {
+	ratio := 0.0
+	if n := s.Games(); n > 0 {
+		ratio = (float64(s.Wins) + float64(s.Draws)/2) / float64(n)
+	}
+	return fmt.Sprintf("%d - %d - %d [%.3f] %d", s.Wins, s.Losses, s.Draws, ratio, s.Games())
+}
+
+// PrintProgress prints the result of a finished game and the running score
+// of the first engine against the second one.
+func PrintProgress(w io.Writer, n int, white, black string, game *chess.Game, first, second string, score Score) {
+	fmt.Fprintf(w, "Finished game %d (%s vs %s): %s {%s}\n", n, white, black, game.Outcome(), method(game))
+	fmt.Fprintf(w, "Score of %s vs %s: %s\n", first, second, score)
+}
+
+// method returns the method by which the game ended.
+func method(game *chess.Game) string {
+	if game.Outcome() == chess.NoOutcome {
+		return "Unterminated"
+	}
+	return game.Method().String()
+}
+
+// printMove prints the summary of the last move played in the game.
+func printMove(w io.Writer, game *chess.Game, m *Move) {
+	positions := game.Positions()
+	// the position the move was played from, as games may start with black to move
+	pos := positions[len(positions)-2]
+	san := chess.AlgebraicNotation{}.Encode(pos, game.Moves()[len(positions)-2])
+
+	number := fmt.Sprintf("%d.", moveNumber(pos))
+	if pos.Turn() == chess.Black {
+		number += ".."
+	}
+
+	fmt.Fprintf(w, "%-6s %-7s %s %s/%d %.2fs\n", number, san, m.Engine, formatScore(m.Info.Score), m.Info.Depth, m.Elapsed.Seconds())
+}
+
+// formatScore formats a score from the engine's point of view,
+// in pawns or as a mate in a number of moves.
+func formatScore(score uci.Score) string {
+	if score.Mate != 0 {
+		return fmt.Sprintf("#%d", score.Mate)
+	}
+	return fmt.Sprintf("%+.2f", float64(score.CP)/100)
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/notnil/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVerbosity(t *testing.T) {
+	tests := []struct {
+		args string
+		want Verbosity
+		err  bool
+	}{
+		{"transcript", Transcript, false},
+		{"moves", Moves, false},
+		{"Progress", Progress, false},
+		{"quiet", Transcript, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			v, err := ParseVerbosity(tt.args)
+			assert.Equal(t, tt.want, v)
+			assert.Equal(t, tt.err, err != nil)
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	score := Score{}
+	score.Add(chess.WhiteWon, true)
+	score.Add(chess.WhiteWon, false)
+	score.Add(chess.BlackWon, false)
+	score.Add(chess.Draw, true)
+	score.Add(chess.NoOutcome, true)
+
+	assert.Equal(t, Score{Wins: 2, Losses: 1, Draws: 1}, score)
+	assert.Equal(t, "2 - 1 - 1 [0.625] 4", score.String())
+	assert.Equal(t, "0 - 0 - 0 [0.000] 0", Score{}.String())
+}
+
+func TestFormatScore(t *testing.T) {
+	tests := []struct {
+		args uci.Score
+		want string
+	}{
+		{uci.Score{CP: 35}, "+0.35"},
+		{uci.Score{CP: -120}, "-1.20"},
+		{uci.Score{CP: 0}, "+0.00"},
+		{uci.Score{Mate: 3}, "#3"},
+		{uci.Score{Mate: -2}, "#-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatScore(tt.args))
+		})
+	}
+}
+
+func TestPrintMove(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		moves []string
+		want  string
+	}{
+		{
+			"starting position",
+			"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+			[]string{"e2e4", "c7c5", "g1f3"},
+			"1.     e4      engine +0.10/1 1.00s\n1...   c5      engine +0.10/1 1.00s\n2.     Nf3     engine +0.10/1 1.00s\n",
+		},
+		{
+			"black to move",
+			"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			[]string{"c7c5", "g1f3"},
+			"1...   c5      engine +0.10/1 1.00s\n2.     Nf3     engine +0.10/1 1.00s\n",
+		},
+		{
+			"later move number",
+			"4k3/8/8/8/8/8/3P4/4K3 w - - 0 20",
+			[]string{"d2d4", "e8d7"},
+			"20.    d4      engine +0.10/1 1.00s\n20...  Kd7     engine +0.10/1 1.00s\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := chess.FEN(tt.fen)
+			require.NoError(t, err)
+			game := chess.NewGame(f)
+
+			b := &strings.Builder{}
+			for _, m := range tt.moves {
+				move, err := chess.UCINotation{}.Decode(game.Position(), m)
+				require.NoError(t, err)
+				require.NoError(t, game.Move(move))
+				printMove(b, game, &Move{
+					Engine:  "engine",
+					Move:    move,
+					Info:    uci.Info{Depth: 1, Score: uci.Score{CP: 10}},
+					Elapsed: time.Second,
+				})
+			}
+
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
@@ -4,9 +4,15 @@ package game
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/leonhfr/cete/internal/uci"
@@ -17,43 +23,144 @@ import (
 
 // Input is a game play input.
 type Input struct {
-	WhiteEngine  string
-	BlackEngine  string
-	WhiteOptions map[string]string
-	BlackOptions map[string]string
-	Time         time.Duration
+	White Player
+	Black Player
+	Time  time.Duration
+	// ID identifies the game in the engine logs and in the live view,
+	// the time the game starts if empty.
+	ID string
+	// Opening is the FEN of the starting position,
+	// the standard starting position if empty.
+	Opening string
+	// LogDir is the directory where the transcript and standard error
+	// of each engine is logged, one file per game and engine.
+	LogDir string
+	// Verbosity is the level of detail printed on the console.
+	Verbosity Verbosity
+	// Console receives the console output, the standard output if nil.
+	Console io.Writer
 }
 
-// Run plays a game.
-func Run(ctx context.Context, input Input) (*chess.Game, error) {
-	white, black, err := startEngines(input)
-	if err != nil {
-		return nil, err
+// Player is the input of a player.
+type Player struct {
+	Engine  string            `json:"engine"`
+	Args    []string          `json:"args,omitempty"`
+	Workdir string            `json:"workdir,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+	// Ponder lets the engine think on the opponent's time.
+	Ponder bool `json:"ponder,omitempty"`
+}
+
+// withID returns the input with an ID, the current time if it has none.
+func (input Input) withID() Input {
+	if input.ID == "" {
+		input.ID = time.Now().Format("20060102-150405")
 	}
+	return input
+}
 
-	defer engine.Close(white)
-	defer engine.Close(black)
+// console returns the writer of the console output.
+func (input Input) console() io.Writer {
+	if input.Console == nil {
+		return os.Stdout
+	}
+	return input.Console
+}
 
-	game := chess.NewGame()
-	for game.Outcome() == chess.NoOutcome {
-		select {
-		case <-ctx.Done():
-			return game, nil
-		default:
-		}
+// config returns the configuration of the player's engine.
+func (p Player) config() engine.Config {
+	env := make([]string, 0, len(p.Env))
+	for k, v := range p.Env {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
 
-		_, err := playMove(game, input.Time, white, black)
+	return engine.Config{
+		Path:   p.Engine,
+		Args:   p.Args,
+		Dir:    p.Workdir,
+		Env:    env,
+		Ponder: p.Ponder,
+	}
+}
+
+// player is an engine playing one side of a game.
+type player struct {
+	name      string
+	engine    *uci.Engine
+	ponder    bool
+	pondering <-chan error
+	expected  *chess.Move
+	log       *engine.Log
+	logFile   *os.File
+}
+
+// Result is a game along with the searches that produced its moves.
+type Result struct {
+	Game *chess.Game
+	// Round is the number of the game in a match, 0 for a single game.
+	Round int
+	// White and Black are the names the engines identify with.
+	White string
+	Black string
+	// Date is the time the game started.
+	Date time.Time
+	// Time is the time per move.
+	Time time.Duration
+	// Moves are the moves played, in order.
+	Moves []Move
+}
+
+// Move is a move played by an engine along with the search that found it.
+type Move struct {
+	// Engine is the base name of the engine that played the move.
+	Engine string
+	Move   *chess.Move
+	// Info is the info line of the principal variation.
+	Info uci.Info
+	// Elapsed is the time the engine was charged for the move.
+	Elapsed time.Duration
+}
+
+// newResult returns the result of a game about to be played.
+func newResult(input Input, white, black *player) (*Result, error) {
+	game := chess.NewGame()
+	if input.Opening != "" {
+		fen, err := chess.FEN(input.Opening)
 		if err != nil {
-			return game, err
+			return nil, err
 		}
+		game = chess.NewGame(fen)
+	}
+
+	return &Result{
+		Game:  game,
+		White: white.engineName(),
+		Black: black.engineName(),
+		Date:  time.Now(),
+		Time:  input.Time,
+	}, nil
+}
+
+// Run plays a game.
+func Run(ctx context.Context, input Input) (*Result, error) {
+	input = input.withID()
+
+	white, black, err := startEngines(input)
+	if err != nil {
+		return nil, err
 	}
+	defer closeEngines(white, black)
 
-	return game, nil
+	return play(ctx, input, white, black, nil, nil)
 }
 
 // RunWithLive plays a game and broadcast it to a live view.
-func RunWithLive(ctx context.Context, input Input, port int) (*chess.Game, error) {
-	view, errc, err := live.New(port, log.New(os.Stdout, "cete: ", 0))
+func RunWithLive(ctx context.Context, input Input, config live.Config) (*Result, error) {
+	input = input.withID()
+
+	view, errc, err := live.New(config, log.New(input.console(), "cete: ", 0))
 	if err != nil {
 		return nil, err
 	}
@@ -63,75 +170,286 @@ func RunWithLive(ctx context.Context, input Input, port int) (*chess.Game, error
 	if err != nil {
 		return nil, err
 	}
-	defer engine.Close(white)
-	defer engine.Close(black)
+	defer closeEngines(white, black)
 
 	view.Wait(ctx)
 
-	game := chess.NewGame()
+	return play(ctx, input, white, black, view, errc)
+}
+
+// Broadcast plays a game and broadcasts it to a live view run by the caller,
+// e.g. to broadcast several games at once. The game is identified by the ID
+// of the input in the live view.
+func Broadcast(ctx context.Context, input Input, view *live.View) (*Result, error) {
+	input = input.withID()
+
+	white, black, err := startEngines(input)
+	if err != nil {
+		return nil, err
+	}
+	defer closeEngines(white, black)
+
+	return play(ctx, input, white, black, view, nil)
+}
+
+// play plays a game between the engines, broadcast to the live view if not nil.
+// The game stops on an error of the live view server received on errc.
+func play(ctx context.Context, input Input, white, black *player, view *live.View, errc <-chan error) (*Result, error) {
+	result, err := newResult(input, white, black)
+	if err != nil {
+		return nil, err
+	}
+
+	game := result.Game
+
+	var board *live.Board
+	var onInfo func(uci.Info)
+	if view != nil {
+		board, err = view.AddGame(input.ID, result.White, result.Black, game.Position())
+		if err != nil {
+			return nil, err
+		}
+
+		if err := board.StartClock(input.Time); err != nil {
+			return nil, err
+		}
+		setPGN(board, result)
+
+		onInfo = func(info uci.Info) {
+			_ = board.Think(info)
+		}
+	}
+
 	for game.Outcome() == chess.NoOutcome {
+		if board != nil {
+			if err := board.Wait(ctx); err != nil {
+				return result, err
+			}
+		}
+
 		select {
 		case <-ctx.Done():
-			return game, nil
+			return result, nil
 		case err := <-errc:
 			if !errors.Is(err, http.ErrServerClosed) {
-				return game, err
+				return result, err
 			}
 		default:
 		}
 
-		move, err := playMove(game, input.Time, white, black)
+		move, err := playMove(game, input.Time, white, black, onInfo)
 		if err != nil {
-			return game, err
+			return result, err
 		}
+		result.Moves = append(result.Moves, *move)
 
-		err = view.Update(move, game.Position())
-		if err != nil {
-			return game, err
+		if input.Verbosity == Moves {
+			printMove(input.console(), game, move)
 		}
+
+		if board != nil {
+			err = board.Update(move.Move, game.Position(), move.Info.Score, move.Elapsed)
+			if err != nil {
+				return result, err
+			}
+			setPGN(board, result)
+		}
+	}
+
+	if board != nil {
+		return result, board.Finish(game.Outcome(), game.Method())
 	}
 
-	return game, err
+	return result, nil
+}
+
+// setPGN sets the PGN of the game on its board of the live view.
+func setPGN(board *live.Board, result *Result) {
+	var sb strings.Builder
+	if err := WritePGN(&sb, result); err == nil {
+		board.SetPGN(sb.String())
+	}
 }
 
 // playMove plays a single move.
-func playMove(game *chess.Game, t time.Duration, white, black *uci.Engine) (*chess.Move, error) {
-	var move *chess.Move
-	var err error
+//
+// If onInfo is not nil, it is called with every info line sent
+// by the engine while it searches the move.
+func playMove(game *chess.Game, t time.Duration, white, black *player, onInfo func(uci.Info)) (*Move, error) {
+	var p *player
 
 	switch game.Position().Turn() {
 	case chess.White:
-		move, err = engine.Search(white, game.Position(), t)
+		p = white
 	case chess.Black:
-		move, err = engine.Search(black, game.Position(), t)
+		p = black
 	case chess.NoColor:
-		err = errors.New("expected valid color")
+		return nil, errors.New("expected valid color")
 	}
 
+	results, elapsed, err := p.search(game, t, onInfo)
 	if err != nil {
-		return move, err
+		return nil, err
+	}
+
+	if results.BestMove == nil {
+		return nil, fmt.Errorf("%s returned no move", p.name)
+	}
+
+	if err := game.Move(results.BestMove); err != nil {
+		return nil, err
+	}
+
+	if p.ponder && results.Ponder != nil && game.Outcome() == chess.NoOutcome {
+		if err := p.startPondering(game, results.Ponder); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Move{
+		Engine:  p.name,
+		Move:    results.BestMove,
+		Info:    principalVariation(results),
+		Elapsed: elapsed,
+	}, nil
+}
+
+// search searches the best move in the game's current position
+// and returns the time the engine was charged for it.
+//
+// If the player was pondering, the ponder search is either resolved
+// with a ponder hit when the opponent played the expected move
+// or stopped before starting a new search.
+func (p *player) search(game *chess.Game, t time.Duration, onInfo func(uci.Info)) (uci.SearchResults, time.Duration, error) {
+	if p.pondering != nil {
+		pondering, expected := p.pondering, p.expected
+		p.pondering, p.expected = nil, nil
+
+		moves := game.Moves()
+		if last := moves[len(moves)-1]; sameMove(last, expected) {
+			start := time.Now()
+			results, err := engine.PonderHit(p.engine, pondering, t, onInfo)
+			return results, time.Since(start), err
+		}
+
+		if err := engine.StopPonder(p.engine, pondering); err != nil {
+			return uci.SearchResults{}, 0, err
+		}
 	}
 
-	if err := game.Move(move); err != nil {
-		return move, err
+	start := time.Now()
+	results, err := engine.Search(p.engine, game, t, onInfo)
+	return results, time.Since(start), err
+}
+
+// startPondering starts pondering on the move the engine expects the opponent to play.
+func (p *player) startPondering(game *chess.Game, expected *chess.Move) error {
+	pondering, err := engine.Ponder(p.engine, game, expected)
+	if err != nil {
+		return err
 	}
 
-	return move, nil
+	p.pondering, p.expected = pondering, expected
+	return nil
+}
+
+// principalVariation returns the info line of the best principal variation.
+func principalVariation(results uci.SearchResults) uci.Info {
+	if len(results.MultiPV) > 0 {
+		return results.MultiPV[0]
+	}
+	return results.Info
+}
+
+// engineName returns the name the engine identifies with,
+// or its base name if it did not send one.
+func (p *player) engineName() string {
+	if name := p.engine.ID()["name"]; name != "" {
+		return name
+	}
+	return p.name
+}
+
+// close stops pondering and shuts down the engine.
+func (p *player) close() {
+	if p.pondering != nil {
+		_ = engine.StopPonder(p.engine, p.pondering)
+		p.pondering, p.expected = nil, nil
+	}
+	engine.Close(p.engine)
+	if p.logFile != nil {
+		_ = p.log.Flush()
+		_ = p.logFile.Close()
+	}
+}
+
+// sameMove reports whether both moves go from and to the same squares with the same promotion.
+func sameMove(m1, m2 *chess.Move) bool {
+	return m1.S1() == m2.S1() && m1.S2() == m2.S2() && m1.Promo() == m2.Promo()
 }
 
 // startEngines starts up both white and black engines
-func startEngines(input Input) (*uci.Engine, *uci.Engine, error) {
-	len := engine.NameLength(input.WhiteEngine, input.BlackEngine)
+func startEngines(input Input) (*player, *player, error) {
+	len := engine.NameLength(input.White.Engine, input.Black.Engine)
 
-	white, err := engine.Start(input.WhiteEngine, len, chess.White, input.WhiteOptions)
+	white, err := startPlayer(input.White, chess.White, len, input)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	black, err := engine.Start(input.BlackEngine, len, chess.Black, input.BlackOptions)
+	black, err := startPlayer(input.Black, chess.Black, len, input)
 	if err != nil {
+		white.close()
 		return nil, nil, err
 	}
 
 	return white, black, nil
 }
+
+// startPlayer starts up the engine of a player
+func startPlayer(p Player, color chess.Color, len int, input Input) (*player, error) {
+	transcript := engine.Transcript{NameLength: len, Color: color}
+	if input.Verbosity == Transcript {
+		transcript.Console = input.console()
+	}
+
+	logFile, err := openLog(input.LogDir, input.ID, color, p)
+	if err != nil {
+		return nil, err
+	}
+	if logFile != nil {
+		transcript.Log = engine.NewLog(logFile)
+	}
+
+	e, err := engine.Start(p.config(), p.Options, transcript)
+	if err != nil {
+		if logFile != nil {
+			_ = transcript.Log.Flush()
+			_ = logFile.Close()
+		}
+		return nil, err
+	}
+
+	return &player{name: path.Base(p.Engine), engine: e, ponder: p.Ponder, log: transcript.Log, logFile: logFile}, nil
+}
+
+// openLog creates the log file of a player's engine in the log directory.
+// No file is created if the log directory is empty.
+func openLog(dir, id string, color chess.Color, p Player) (*os.File, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s-%s-%s.log", id, strings.ToLower(color.Name()), path.Base(p.Engine))
+	return os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+}
+
+// closeEngines shuts down both white and black engines
+func closeEngines(white, black *player) {
+	white.close()
+	black.close()
+}
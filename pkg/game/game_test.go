@@ -0,0 +1,147 @@
+package game
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/notnil/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayMovePonder(t *testing.T) {
+	e, commands := fakeEngine(map[string][]string{
+		"go movetime 50": {"bestmove e2e4 ponder e7e5"},
+		"stop":           {"bestmove (none)"},
+	})
+	white := &player{name: "white", engine: e, ponder: true}
+
+	game := chess.NewGame()
+	m, err := playMove(game, 50*time.Millisecond, white, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "e2e4", m.Move.String())
+
+	require.NotNil(t, white.pondering)
+	assert.Equal(t, "e7e5", white.expected.String())
+
+	// closing the player waits for the engine to answer, once all commands are received
+	white.close()
+	assert.Nil(t, white.pondering)
+	assert.Equal(t, []string{"position startpos", "go movetime 50", "position startpos moves e2e4 e7e5", "go ponder", "stop"}, commands())
+}
+
+func TestPlayerSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  []string
+	}{
+		{
+			"ponder hit",
+			"e7e5",
+			[]string{"position startpos moves e2e4 e7e5", "go ponder", "ponderhit"},
+		},
+		{
+			"ponder miss",
+			"c7c5",
+			[]string{"position startpos moves e2e4 e7e5", "go ponder", "stop", "position startpos moves e2e4 c7c5", "go movetime 50"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, commands := fakeEngine(map[string][]string{
+				"ponderhit":      {"bestmove g1f3"},
+				"stop":           {"bestmove g1f3"},
+				"go movetime 50": {"bestmove g1f3"},
+			})
+			p := &player{name: "white", engine: e, ponder: true}
+			defer p.close()
+
+			game := newGame(t, "e2e4")
+			require.NoError(t, p.startPondering(game, move(t, game, "e7e5")))
+			require.NoError(t, game.Move(move(t, game, tt.reply)))
+
+			results, _, err := p.search(game, 50*time.Millisecond, nil)
+			require.NoError(t, err)
+			assert.Equal(t, "g1f3", results.BestMove.String())
+			assert.Nil(t, p.pondering)
+			assert.Equal(t, tt.want, commands())
+		})
+	}
+}
+
+func TestSameMove(t *testing.T) {
+	pos := position(t, "4k3/4P3/8/8/8/8/8/4K3 w - - 0 1")
+	other := position(t, "8/4P3/8/8/8/8/8/k3K3 w - - 0 1")
+
+	tests := []struct {
+		name string
+		m1   *chess.Move
+		m2   *chess.Move
+		want bool
+	}{
+		{"same move", decode(t, pos, "e1d2"), decode(t, pos, "e1d2"), true},
+		// the move gives check in one position only
+		{"same squares in other positions", decode(t, pos, "e7e8q"), decode(t, other, "e7e8q"), true},
+		{"other promotion", decode(t, other, "e7e8q"), decode(t, other, "e7e8n"), false},
+		{"other squares", decode(t, pos, "e1d2"), decode(t, pos, "e1f2"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sameMove(tt.m1, tt.m2))
+		})
+	}
+}
+
+// fakeEngine returns an engine answering the commands from the script,
+// along with a function returning the commands it received.
+func fakeEngine(script map[string][]string) (*uci.Engine, func() []string) {
+	mu := &sync.Mutex{}
+	var commands []string
+	e := uci.NewFake(func(cmd string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		if cmd != "quit" {
+			commands = append(commands, cmd)
+		}
+		return script[cmd]
+	})
+
+	return e, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, commands...)
+	}
+}
+
+// newGame returns a game from the starting position after the moves.
+func newGame(t *testing.T, moves ...string) *chess.Game {
+	game := chess.NewGame()
+	for _, m := range moves {
+		require.NoError(t, game.Move(move(t, game, m)))
+	}
+	return game
+}
+
+// move decodes a move in UCI notation on the game's current position.
+func move(t *testing.T, game *chess.Game, s string) *chess.Move {
+	return decode(t, game.Position(), s)
+}
+
+// position parses a FEN.
+func position(t *testing.T, fen string) *chess.Position {
+	pos := &chess.Position{}
+	require.NoError(t, pos.UnmarshalText([]byte(fen)))
+	return pos
+}
+
+// decode decodes a move in UCI notation.
+func decode(t *testing.T, pos *chess.Position, s string) *chess.Move {
+	m, err := chess.UCINotation{}.Decode(pos, s)
+	require.NoError(t, err)
+	return m
+}
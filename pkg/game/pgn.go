@@ -0,0 +1,184 @@
+package game
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+// pgnLineLength is the maximum length of a movetext line
+// in the PGN export format.
+const pgnLineLength = 79
+
+// startingFEN is the FEN of the standard starting position.
+var startingFEN = chess.StartingPosition().String()
+
+// WritePGN writes the game in PGN format, each move being annotated with
+// a comment holding the score and depth of the search that produced it
+// and the time spent, e.g. {+0.35/18 0.52s}.
+func WritePGN(w io.Writer, r *Result) error {
+	var sb strings.Builder
+
+	for _, tag := range r.tags() {
+		fmt.Fprintf(&sb, "[%s %q]\n", tag[0], tag[1])
+	}
+	sb.WriteString("\n")
+	sb.WriteString(wrap(r.movetext(), pgnLineLength))
+	sb.WriteString("\n\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// AppendPGN appends the game in PGN format to a file, creating it if needed.
+// The file is synced to disk before returning so that finished games
+// are not lost if the run is interrupted.
+func AppendPGN(filename string, r *Result) error {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	if err := WritePGN(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// tags returns the PGN tag pairs of the game, starting with the seven tag roster.
+func (r *Result) tags() [][2]string {
+	site, err := os.Hostname()
+	if err != nil || site == "" {
+		site = "?"
+	}
+
+	round := "-"
+	if r.Round > 0 {
+		round = strconv.Itoa(r.Round)
+	}
+
+	tags := [][2]string{
+		{"Event", "cete"},
+		{"Site", site},
+		{"Date", r.Date.Format("2006.01.02")},
+		{"Round", round},
+		{"White", r.White},
+		{"Black", r.Black},
+		{"Result", r.Game.Outcome().String()},
+	}
+
+	if fen := r.Game.Positions()[0].String(); fen != startingFEN {
+		tags = append(tags, [2]string{"SetUp", "1"}, [2]string{"FEN", fen})
+	}
+
+	return append(tags,
+		[2]string{"TimeControl", timeControl(r.Time)},
+		[2]string{"Termination", termination(r.Game)},
+		[2]string{"PlyCount", strconv.Itoa(len(r.Game.Moves()))},
+	)
+}
+
+// movetext returns the moves of the game with their comments,
+// followed by the game termination marker.
+func (r *Result) movetext() []string {
+	positions := r.Game.Positions()
+	moves := r.Game.Moves()
+	tokens := make([]string, 0, 3*len(moves)+2)
+
+	commented := false
+	for i, move := range moves {
+		pos := positions[i]
+		switch {
+		case pos.Turn() == chess.White:
+			tokens = append(tokens, fmt.Sprintf("%d.", moveNumber(pos)))
+		case i == 0 || commented:
+			tokens = append(tokens, fmt.Sprintf("%d...", moveNumber(pos)))
+		}
+
+		tokens = append(tokens, chess.AlgebraicNotation{}.Encode(pos, move))
+
+		commented = i < len(r.Moves)
+		if commented {
+			tokens = append(tokens, comment(r.Moves[i]))
+		}
+	}
+
+	if r.Game.Outcome() != chess.NoOutcome {
+		tokens = append(tokens, fmt.Sprintf("{%s}", r.Game.Method()))
+	}
+
+	return append(tokens, r.Game.Outcome().String())
+}
+
+// moveNumber returns the full move number of the position.
+func moveNumber(pos *chess.Position) int {
+	fields := strings.Fields(pos.String())
+	n, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return 1
+	}
+	return n
+}
+
+// comment returns the comment annotating a move.
+//
+// The score and depth are omitted if the engine did not send a score,
+// e.g. if it answered a ponder hit right away.
+func comment(m Move) string {
+	if newRecordScore(m.Info) == nil {
+		return fmt.Sprintf("{%.2fs}", m.Elapsed.Seconds())
+	}
+	return fmt.Sprintf("{%s/%d %.2fs}", formatScore(m.Info.Score), m.Info.Depth, m.Elapsed.Seconds())
+}
+
+// timeControl returns the PGN time control of a fixed time per move.
+func timeControl(t time.Duration) string {
+	if t <= 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(t.Seconds(), 'f', -1, 64) + "/move"
+}
+
+// termination returns the PGN termination of the game.
+func termination(game *chess.Game) string {
+	if game.Outcome() == chess.NoOutcome {
+		return "unterminated"
+	}
+	return "normal"
+}
+
+// wrap joins the tokens with spaces, breaking lines before they exceed
+// the given length.
+func wrap(tokens []string, length int) string {
+	var sb strings.Builder
+
+	n := 0
+	for _, token := range tokens {
+		switch {
+		case n == 0:
+		case n+1+len(token) > length:
+			sb.WriteString("\n")
+			n = 0
+		default:
+			sb.WriteString(" ")
+			n++
+		}
+
+		sb.WriteString(token)
+		n += len(token)
+	}
+
+	return sb.String()
+}
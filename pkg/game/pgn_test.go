@@ -0,0 +1,134 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/notnil/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMovetext(t *testing.T) {
+	tests := []struct {
+		name  string
+		fen   string
+		moves []string
+		want  string
+	}{
+		{
+			"starting position",
+			"",
+			[]string{"f2f3", "e7e5", "g2g4", "d8h4"},
+			"1. f3 {+0.10/1 1.00s} 1... e5 {+0.10/1 1.00s} 2. g4 {+0.10/1 1.00s} 2... Qh4# {+0.10/1 1.00s} {Checkmate} 0-1",
+		},
+		{
+			"black to move",
+			"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			[]string{"c7c5", "g1f3"},
+			"1... c5 {+0.10/1 1.00s} 2. Nf3 {+0.10/1 1.00s} *",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestResult(t, tt.fen, tt.moves)
+			assert.Equal(t, tt.want, strings.Join(r.movetext(), " "))
+		})
+	}
+}
+
+func TestComment(t *testing.T) {
+	tests := []struct {
+		name string
+		info uci.Info
+		want string
+	}{
+		{"score", uci.Info{Depth: 18, Score: uci.Score{CP: 35}}, "{+0.35/18 0.52s}"},
+		{"mate", uci.Info{Depth: 12, Score: uci.Score{Mate: -3}}, "{#-3/12 0.52s}"},
+		{"no score", uci.Info{}, "{0.52s}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, comment(Move{Info: tt.info, Elapsed: 520 * time.Millisecond}))
+		})
+	}
+}
+
+func TestTags(t *testing.T) {
+	fen := "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"
+	r := newTestResult(t, fen, []string{"c7c5"})
+
+	tags := map[string]string{}
+	for _, tag := range r.tags() {
+		tags[tag[0]] = tag[1]
+	}
+
+	assert.Equal(t, "2022.10.03", tags["Date"])
+	assert.Equal(t, "White 1.0", tags["White"])
+	assert.Equal(t, "Black 1.0", tags["Black"])
+	assert.Equal(t, "*", tags["Result"])
+	assert.Equal(t, "1", tags["SetUp"])
+	assert.Equal(t, fen, tags["FEN"])
+	assert.Equal(t, "0.5/move", tags["TimeControl"])
+	assert.Equal(t, "unterminated", tags["Termination"])
+	assert.Equal(t, "1", tags["PlyCount"])
+}
+
+func TestWrap(t *testing.T) {
+	tokens := []string{"1.", "e4", "{+0.35/18 0.52s}", "1...", "e5"}
+	assert.Equal(t, "1. e4 {+0.35/18 0.52s} 1... e5", wrap(tokens, 79))
+	assert.Equal(t, "1. e4\n{+0.35/18 0.52s}\n1... e5", wrap(tokens, 16))
+}
+
+func newTestResult(t *testing.T, fen string, moves []string) *Result {
+	t.Helper()
+
+	game := chess.NewGame()
+	if fen != "" {
+		f, err := chess.FEN(fen)
+		require.NoError(t, err)
+		game = chess.NewGame(f)
+	}
+
+	r := &Result{
+		Game:  game,
+		White: "White 1.0",
+		Black: "Black 1.0",
+		Date:  time.Date(2022, 10, 3, 12, 0, 0, 0, time.UTC),
+		Time:  500 * time.Millisecond,
+	}
+
+	for _, m := range moves {
+		move, err := chess.UCINotation{}.Decode(game.Position(), m)
+		require.NoError(t, err)
+		require.NoError(t, game.Move(move))
+		r.Moves = append(r.Moves, Move{
+			Move:    move,
+			Info:    uci.Info{Depth: 1, Score: uci.Score{CP: 10}},
+			Elapsed: time.Second,
+		})
+	}
+
+	return r
+}
+
+func TestAppendPGN(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "games.pgn")
+	r := newTestResult(t, "", []string{"f2f3", "e7e5", "g2g4", "d8h4"})
+
+	require.NoError(t, AppendPGN(filename, r))
+	require.NoError(t, AppendPGN(filename, r))
+
+	contents, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, WritePGN(&sb, r))
+	assert.Equal(t, sb.String()+sb.String(), string(contents))
+}
@@ -0,0 +1,130 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/notnil/chess"
+)
+
+type (
+	// Record is the structured record of a game, meant to be encoded in JSON.
+	Record struct {
+		Round       int          `json:"round,omitempty"`
+		White       RecordPlayer `json:"white"`
+		Black       RecordPlayer `json:"black"`
+		Date        time.Time    `json:"date"`
+		TimeControl string       `json:"time_control"`
+		// Opening is the FEN of the starting position.
+		Opening     string       `json:"opening"`
+		Result      string       `json:"result"`
+		Termination string       `json:"termination"`
+		Moves       []RecordMove `json:"moves"`
+	}
+
+	// RecordPlayer is the record of a player.
+	RecordPlayer struct {
+		// Name is the name the engine identifies with.
+		Name    string            `json:"name"`
+		Engine  string            `json:"engine"`
+		Args    []string          `json:"args,omitempty"`
+		Options map[string]string `json:"options,omitempty"`
+		Ponder  bool              `json:"ponder"`
+	}
+
+	// RecordMove is the record of a move and of the search that found it.
+	RecordMove struct {
+		UCI   string       `json:"uci"`
+		SAN   string       `json:"san"`
+		Score *RecordScore `json:"score,omitempty"`
+		Depth int          `json:"depth"`
+		Nodes int64        `json:"nodes"`
+		NPS   int64        `json:"nps"`
+		// Time is the time the engine was charged for the move, in milliseconds.
+		Time int64    `json:"time"`
+		PV   []string `json:"pv"`
+	}
+
+	// RecordScore is the score of a move from the engine's point of view,
+	// either in centipawns or as a mate in a number of moves.
+	RecordScore struct {
+		CP   *int `json:"cp,omitempty"`
+		Mate *int `json:"mate,omitempty"`
+	}
+)
+
+// NewRecord returns the record of a game played from the input.
+func NewRecord(input Input, r *Result) Record {
+	positions, played := r.Game.Positions(), r.Game.Moves()
+
+	moves := make([]RecordMove, 0, len(r.Moves))
+	for i, m := range r.Moves {
+		moves = append(moves, newRecordMove(positions[i], played[i], m.Info, m.Elapsed))
+	}
+
+	return Record{
+		Round:       r.Round,
+		White:       newRecordPlayer(input.White, r.White),
+		Black:       newRecordPlayer(input.Black, r.Black),
+		Date:        r.Date,
+		TimeControl: timeControl(r.Time),
+		Opening:     positions[0].String(),
+		Result:      r.Game.Outcome().String(),
+		Termination: method(r.Game),
+		Moves:       moves,
+	}
+}
+
+// WriteJSON writes the record of a game as a single line of JSON.
+func WriteJSON(w io.Writer, input Input, r *Result) error {
+	return json.NewEncoder(w).Encode(NewRecord(input, r))
+}
+
+// newRecordPlayer returns the record of a player.
+func newRecordPlayer(p Player, name string) RecordPlayer {
+	return RecordPlayer{
+		Name:    name,
+		Engine:  p.Engine,
+		Args:    p.Args,
+		Options: p.Options,
+		Ponder:  p.Ponder,
+	}
+}
+
+// newRecordMove returns the record of a move played in the position
+// and of the search that found it.
+//
+// The move must be the one recorded by the game, as it is tagged with checks.
+func newRecordMove(pos *chess.Position, move *chess.Move, info uci.Info, elapsed time.Duration) RecordMove {
+	pv := make([]string, 0, len(info.PV))
+	for _, m := range info.PV {
+		pv = append(pv, m.String())
+	}
+
+	return RecordMove{
+		UCI:   move.String(),
+		SAN:   chess.AlgebraicNotation{}.Encode(pos, move),
+		Score: newRecordScore(info),
+		Depth: info.Depth,
+		Nodes: info.Nodes,
+		NPS:   info.NPS,
+		Time:  elapsed.Milliseconds(),
+		PV:    pv,
+	}
+}
+
+// newRecordScore returns the score of the search, nil if the engine did not send one.
+func newRecordScore(info uci.Info) *RecordScore {
+	switch {
+	case info.Score.Mate != 0:
+		mate := info.Score.Mate
+		return &RecordScore{Mate: &mate}
+	case info.Depth > 0:
+		cp := info.Score.CP
+		return &RecordScore{CP: &cp}
+	default:
+		return nil
+	}
+}
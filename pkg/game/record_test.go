@@ -0,0 +1,45 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecord(t *testing.T) {
+	r := newTestResult(t, "", []string{"f2f3", "e7e5", "g2g4", "d8h4"})
+	r.Moves[3].Info = uci.Info{Depth: 2, Score: uci.Score{Mate: 1}, Nodes: 42, NPS: 4200, PV: r.Moves[3].Info.PV}
+	input := Input{
+		White: Player{Engine: "/engines/white", Options: map[string]string{"Hash": "32"}},
+		Black: Player{Engine: "black", Ponder: true},
+	}
+
+	record := NewRecord(input, r)
+
+	assert.Equal(t, RecordPlayer{Name: "White 1.0", Engine: "/engines/white", Options: map[string]string{"Hash": "32"}}, record.White)
+	assert.Equal(t, RecordPlayer{Name: "Black 1.0", Engine: "black", Ponder: true}, record.Black)
+	assert.Equal(t, "0.5/move", record.TimeControl)
+	assert.Equal(t, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", record.Opening)
+	assert.Equal(t, "0-1", record.Result)
+	assert.Equal(t, "Checkmate", record.Termination)
+	require.Len(t, record.Moves, 4)
+
+	cp, mate := 10, 1
+	assert.Equal(t, RecordMove{UCI: "f2f3", SAN: "f3", Score: &RecordScore{CP: &cp}, Depth: 1, Time: 1000, PV: []string{}}, record.Moves[0])
+	assert.Equal(t, RecordMove{UCI: "d8h4", SAN: "Qh4#", Score: &RecordScore{Mate: &mate}, Depth: 2, Nodes: 42, NPS: 4200, Time: 1000, PV: []string{}}, record.Moves[3])
+}
+
+func TestWriteJSON(t *testing.T) {
+	r := newTestResult(t, "", []string{"e2e4"})
+
+	var sb strings.Builder
+	require.NoError(t, WriteJSON(&sb, Input{}, r))
+
+	assert.True(t, strings.HasSuffix(sb.String(), "}\n"))
+	assert.Equal(t, 1, strings.Count(sb.String(), "\n"))
+	assert.Contains(t, sb.String(), `{"uci":"e2e4","san":"e4","score":{"cp":10},"depth":1,"nodes":0,"nps":0,"time":1000,"pv":[]}`)
+	assert.Contains(t, sb.String(), `"termination":"Unterminated"`)
+}
@@ -0,0 +1,106 @@
+package live
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// gameState is the state of a game served by the REST API.
+type gameState struct {
+	summary
+	History     history      `json:"history"`
+	Evaluations []evaluation `json:"evaluations"`
+	Clock       clockState   `json:"clock"`
+}
+
+// state returns the state of the game.
+// It must be called with the mutex locked.
+func (b *Board) state() gameState {
+	evaluations := b.evaluations
+	if evaluations == nil {
+		evaluations = []evaluation{}
+	}
+
+	return gameState{
+		summary:     b.summary(),
+		History:     b.history,
+		Evaluations: evaluations,
+		Clock:       b.clock.state(time.Now()),
+	}
+}
+
+// apiGamesHandler serves the summaries of the games
+func (v *View) apiGamesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	v.mu.Lock()
+	games := v.summaries()
+	v.mu.Unlock()
+
+	v.writeJSON(w, games)
+}
+
+// apiGameHandler serves the state of a game at /api/games/{id}
+// and its PGN at /api/games/{id}/pgn
+func (v *View) apiGameHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, resource, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/games/"), "/")
+	b, ok := v.board(id)
+	if !ok || (resource != "" && resource != "pgn") {
+		http.NotFound(w, r)
+		return
+	}
+
+	v.mu.Lock()
+	state, pgn := b.state(), b.pgn
+	v.mu.Unlock()
+
+	if resource == "pgn" {
+		w.Header().Set("Content-Type", "application/x-chess-pgn")
+		_, _ = w.Write([]byte(pgn))
+		return
+	}
+
+	v.writeJSON(w, state)
+}
+
+// apiStandingsHandler serves the standings of the match, if any
+func (v *View) apiStandingsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	v.mu.Lock()
+	standings := v.standings
+	v.mu.Unlock()
+
+	if standings == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	v.writeJSON(w, standings)
+}
+
+// writeJSON writes the value as a JSON response.
+func (v *View) writeJSON(w http.ResponseWriter, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		v.logger.Printf("%v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
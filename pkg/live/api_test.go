@@ -0,0 +1,68 @@
+package live
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/notnil/chess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI(t *testing.T) {
+	v, _, err := New(Config{}, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+	defer func() { _ = v.Shutdown() }()
+
+	fen := chess.StartingPosition().String()
+	b, err := v.AddGame("1", "white", "black", chess.StartingPosition())
+	require.NoError(t, err)
+	b.SetPGN("1. e4 *")
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		wantCode    int
+		wantType    string
+		wantBody    string
+		containBody bool
+	}{
+		{"games", http.MethodGet, "/api/games", http.StatusOK, "application/json",
+			`[{"id":"1","white":"white","black":"black","position":"` + fen + `"}]`, false},
+		{"game", http.MethodGet, "/api/games/1", http.StatusOK, "application/json",
+			`{"id":"1","white":"white","black":"black","position":"` + fen + `","history":{"start":"` + fen + `","moves":[]},"evaluations":[],"clock":`, true},
+		{"pgn", http.MethodGet, "/api/games/1/pgn", http.StatusOK, "application/x-chess-pgn", "1. e4 *", false},
+		{"unknown game", http.MethodGet, "/api/games/2", http.StatusNotFound, "", "", false},
+		{"unknown resource", http.MethodGet, "/api/games/1/fen", http.StatusNotFound, "", "", false},
+		{"no standings", http.MethodGet, "/api/standings", http.StatusNotFound, "", "", false},
+		{"method not allowed", http.MethodPost, "/api/games", http.StatusMethodNotAllowed, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			v.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, tt.wantType, rec.Header().Get("Content-Type"))
+			if tt.containBody {
+				assert.Contains(t, rec.Body.String(), tt.wantBody)
+			} else {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+
+	require.NoError(t, v.SetStandings(map[string]int{"points": 1}))
+	rec := httptest.NewRecorder()
+	v.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/standings", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"points":1}`, rec.Body.String())
+}
@@ -0,0 +1,135 @@
+package live
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// validate validates the configuration.
+func (c Config) validate() error {
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return errors.New("live: both a TLS certificate and key are required")
+	}
+
+	if (c.User == "") != (c.Password == "") {
+		return errors.New("live: both a user and a password are required")
+	}
+
+	if c.Private && !c.protected() {
+		return errors.New("live: a private view requires a password or a token")
+	}
+
+	return nil
+}
+
+// protected reports whether the configuration has credentials.
+func (c Config) protected() bool {
+	return c.Password != "" || c.Token != ""
+}
+
+// url returns the URL of the view.
+func (c Config) url() string {
+	scheme := "http"
+	if c.TLSCert != "" {
+		scheme = "https"
+	}
+
+	host := c.Address
+	if host == "" {
+		host = "localhost"
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(c.Port)))
+}
+
+// tokenCookie is the cookie the token is kept in once passed in the token
+// query parameter, so that the pages of the view and their assets and
+// endpoints are authorized as well.
+const tokenCookie = "cete-token"
+
+// authorized reports whether the request holds the credentials of the view,
+// either its user and password or its token in the Authorization header,
+// the token query parameter or the token cookie.
+func (c Config) authorized(r *http.Request) bool {
+	if !c.protected() {
+		return true
+	}
+
+	if c.Password != "" {
+		if user, password, ok := r.BasicAuth(); ok && equal(user, c.User) && equal(password, c.Password) {
+			return true
+		}
+	}
+
+	if c.Token != "" {
+		if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") && equal(strings.TrimPrefix(header, "Bearer "), c.Token) {
+			return true
+		}
+		if token := r.URL.Query().Get("token"); token != "" && equal(token, c.Token) {
+			return true
+		}
+		if cookie, err := r.Cookie(tokenCookie); err == nil && equal(cookie.Value, c.Token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equal compares the strings in constant time.
+func equal(s1, s2 string) bool {
+	return subtle.ConstantTimeCompare([]byte(s1), []byte(s2)) == 1
+}
+
+// spectate protects the spectating endpoints when the view is private.
+// Otherwise it only remembers a valid token passed in the URL, so that
+// the controls of the page opened with it are authorized.
+func (v *View) spectate(next http.Handler) http.Handler {
+	if v.config.Private {
+		return v.authorize(next)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v.setTokenCookie(w, r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorize rejects the requests without the credentials of the view,
+// protecting the control endpoints.
+func (v *View) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !v.config.authorized(r) {
+			if v.config.Password != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="cete", charset="UTF-8"`)
+			}
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		v.setTokenCookie(w, r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setTokenCookie stores the token passed in the URL in a cookie when valid,
+// so that the browser sends it with the following requests.
+func (v *View) setTokenCookie(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if v.config.Token == "" || !equal(token, v.config.Token) {
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     tokenCookie,
+		Value:    token,
+		Path:     "/",
+		Secure:   v.config.TLSCert != "",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
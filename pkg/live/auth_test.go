@@ -0,0 +1,114 @@
+package live
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"open", Config{}, false},
+		{"tls", Config{TLSCert: "cert.pem", TLSKey: "key.pem"}, false},
+		{"tls without key", Config{TLSCert: "cert.pem"}, true},
+		{"basic auth", Config{User: "user", Password: "password"}, false},
+		{"user without password", Config{User: "user"}, true},
+		{"password without user", Config{Password: "password"}, true},
+		{"private", Config{Token: "token", Private: true}, false},
+		{"private without credentials", Config{Private: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfigURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:6061", Config{Port: 6061}.url())
+	assert.Equal(t, "https://127.0.0.1:443", Config{Address: "127.0.0.1", Port: 443, TLSCert: "cert.pem"}.url())
+	assert.Equal(t, "http://[::1]:6061", Config{Address: "::1", Port: 6061}.url())
+}
+
+func TestConfigAuthorized(t *testing.T) {
+	config := Config{User: "user", Password: "password", Token: "token"}
+
+	tests := []struct {
+		name    string
+		config  Config
+		request func(r *http.Request)
+		want    bool
+	}{
+		{"open", Config{}, func(r *http.Request) {}, true},
+		{"no credentials", config, func(r *http.Request) {}, false},
+		{"basic auth", config, func(r *http.Request) { r.SetBasicAuth("user", "password") }, true},
+		{"wrong password", config, func(r *http.Request) { r.SetBasicAuth("user", "token") }, false},
+		{"bearer token", config, func(r *http.Request) { r.Header.Set("Authorization", "Bearer token") }, true},
+		{"wrong bearer token", config, func(r *http.Request) { r.Header.Set("Authorization", "Bearer password") }, false},
+		{"query token", config, func(r *http.Request) { r.URL.RawQuery = "token=token" }, true},
+		{"cookie token", config, func(r *http.Request) { r.AddCookie(&http.Cookie{Name: tokenCookie, Value: "token"}) }, true},
+		{"token without token", Config{User: "user", Password: "password"}, func(r *http.Request) { r.URL.RawQuery = "token=" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/start", nil)
+			tt.request(r)
+			assert.Equal(t, tt.want, tt.config.authorized(r))
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name          string
+		config        Config
+		path          string
+		wantControl   int
+		wantSpectate  int
+		wantCookie    bool
+		wantChallenge bool
+	}{
+		{"open", Config{}, "/", http.StatusOK, http.StatusOK, false, false},
+		{"protected", Config{User: "user", Password: "password"}, "/", http.StatusUnauthorized, http.StatusOK, false, true},
+		{"token", Config{Token: "token"}, "/", http.StatusUnauthorized, http.StatusOK, false, false},
+		{"token in url", Config{Token: "token"}, "/?token=token", http.StatusOK, http.StatusOK, true, false},
+		{"invalid token in url", Config{Token: "token"}, "/?token=other", http.StatusUnauthorized, http.StatusOK, false, false},
+		{"token", Config{Token: "token"}, "/", http.StatusUnauthorized, http.StatusOK, false, false},
+		{"token in url", Config{Token: "token"}, "/?token=token", http.StatusOK, http.StatusOK, true, false},
+		{"invalid token in url", Config{Token: "token"}, "/?token=other", http.StatusUnauthorized, http.StatusOK, false, false},
+		{"private", Config{Token: "token", Private: true}, "/", http.StatusUnauthorized, http.StatusUnauthorized, false, false},
+		{"private with token", Config{Token: "token", Private: true}, "/?token=token", http.StatusOK, http.StatusOK, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &View{config: tt.config}
+
+			rec := httptest.NewRecorder()
+			v.authorize(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, nil))
+			assert.Equal(t, tt.wantControl, rec.Code)
+			assert.Equal(t, tt.wantChallenge, rec.Header().Get("WWW-Authenticate") != "")
+			assert.Equal(t, tt.wantCookie, len(rec.Result().Cookies()) > 0)
+
+			rec = httptest.NewRecorder()
+			v.spectate(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Equal(t, tt.wantSpectate, rec.Code)
+			assert.Equal(t, tt.wantCookie, len(rec.Result().Cookies()) > 0)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/notnil/chess"
+)
+
+// Board is a game broadcast in a live view.
+//
+// Its state is guarded by the mutex of the view, which also broadcasts
+// the summary of the game to the index of the games whenever it changes.
+type Board struct {
+	id          string
+	white       string
+	black       string
+	view        *View
+	clock       clock
+	evaluations []evaluation
+	history     history
+	pgn         string
+	position    *chess.Position
+	result      *result
+	subscribers map[*subscriber]struct{}
+	thought     time.Time
+}
+
+// StartClock starts the clock of the side to move, each side being given
+// the time per move when its clock starts.
+func (b *Board) StartClock(moveTime time.Duration) error {
+	return b.setClock(func(c *clock, now time.Time) {
+		*c = newClock(moveTime)
+		c.start(b.position.Turn(), now)
+	})
+}
+
+// Wait blocks while the live view is paused, the clock being stopped
+// meanwhile. It is called before the engine to move starts searching.
+func (b *Board) Wait(ctx context.Context) error {
+	resumed := b.view.paused()
+	if resumed == nil {
+		return nil
+	}
+
+	if err := b.setClock(func(c *clock, _ time.Time) { c.halt() }); err != nil {
+		return err
+	}
+
+	select {
+	case <-resumed:
+	case <-ctx.Done():
+		return nil
+	}
+
+	return b.setClock(func(c *clock, now time.Time) { c.start(b.position.Turn(), now) })
+}
+
+// setClock updates the clock and broadcasts its state.
+func (b *Board) setClock(update func(c *clock, now time.Time)) error {
+	b.view.mu.Lock()
+	defer b.view.mu.Unlock()
+
+	now := time.Now()
+	update(&b.clock, now)
+
+	state := b.clock.state(now)
+	msg, err := json.Marshal(&message{Clock: &state})
+	if err != nil {
+		return err
+	}
+
+	broadcast(b.subscribers, msg)
+	return nil
+}
+
+// Update updates the board with the latest move and position,
+// the score of the move from the point of view of the engine that played it
+// and the time it was charged for it.
+//
+// The clock of the side that moved is stopped and the clock of the side
+// to move is started, unless the game is over.
+func (b *Board) Update(move *chess.Move, position *chess.Position, score uci.Score, elapsed time.Duration) error {
+	b.view.mu.Lock()
+	defer b.view.mu.Unlock()
+
+	eval := newEvaluation(len(b.evaluations)+1, position.Turn().Other(), score)
+	b.evaluations = append(b.evaluations, eval)
+	played := newHistoryMove(b.position, move, position)
+	b.history.Moves = append(b.history.Moves, played)
+	b.position = position
+
+	now := time.Now()
+	b.clock.stop(elapsed)
+	if position.Status() == chess.NoMethod {
+		b.clock.start(position.Turn(), now)
+	}
+
+	b.thought = time.Time{}
+
+	state := b.clock.state(now)
+	msg, err := json.Marshal(&message{Move: move, Position: position, Evaluation: &eval, Clock: &state, Played: &played})
+	if err != nil {
+		return err
+	}
+
+	broadcast(b.subscribers, msg)
+	return b.view.broadcastIndex()
+}
+
+// Think updates the board with an info line of the engine searching
+// the current position.  Lines without a principal variation or of secondary
+// variations are ignored, and the others are throttled to keep the number
+// of messages sent to the subscribers reasonable.
+func (b *Board) Think(info uci.Info) error {
+	if len(info.PV) == 0 || info.Multipv > 1 {
+		return nil
+	}
+
+	b.view.mu.Lock()
+	defer b.view.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(b.thought) < b.view.thinkingInterval {
+		return nil
+	}
+	b.thought = now
+
+	t := newThinking(b.position, info)
+	msg, err := json.Marshal(&message{Thinking: &t})
+	if err != nil {
+		return err
+	}
+
+	broadcastLatest(b.subscribers, msg)
+	return nil
+}
+
+// Finish marks the game as over and stops the clock.
+func (b *Board) Finish(outcome chess.Outcome, method chess.Method) error {
+	b.view.mu.Lock()
+	defer b.view.mu.Unlock()
+
+	b.clock.halt()
+	b.result = &result{Outcome: outcome.String(), Method: method.String()}
+
+	state := b.clock.state(time.Now())
+	msg, err := json.Marshal(&message{Clock: &state, Result: b.result})
+	if err != nil {
+		return err
+	}
+
+	broadcast(b.subscribers, msg)
+	return b.view.broadcastIndex()
+}
+
+// SetPGN sets the PGN of the game served by the REST API,
+// kept up to date by the caller as the game goes.
+func (b *Board) SetPGN(pgn string) {
+	b.view.mu.Lock()
+	defer b.view.mu.Unlock()
+	b.pgn = pgn
+}
+
+// summary returns the summary of the game listed in the index.
+// It must be called with the mutex locked.
+func (b *Board) summary() summary {
+	s := summary{
+		ID:       b.id,
+		White:    b.white,
+		Black:    b.black,
+		Position: b.position.String(),
+		Result:   b.result,
+	}
+	if n := len(b.evaluations); n > 0 {
+		last := b.evaluations[n-1]
+		s.Last = &last
+	}
+	return s
+}
+
+// addSubscriber adds a subscriber and returns the message setting
+// the current state of the game, which the subscriber must be sent
+// before any other message
+func (b *Board) addSubscriber(s *subscriber) ([]byte, error) {
+	b.view.mu.Lock()
+	defer b.view.mu.Unlock()
+	b.subscribers[s] = struct{}{}
+
+	state := b.clock.state(time.Now())
+	return json.Marshal(&message{
+		Position:    b.position,
+		Evaluations: b.evaluations,
+		Clock:       &state,
+		History:     &b.history,
+		Players:     &players{White: b.white, Black: b.black},
+		Result:      b.result,
+	})
+}
+
+// deleteSubscriber deletes a subscriber
+func (b *Board) deleteSubscriber(s *subscriber) {
+	b.view.mu.Lock()
+	defer b.view.mu.Unlock()
+	delete(b.subscribers, s)
+}
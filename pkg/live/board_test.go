@@ -0,0 +1,80 @@
+package live
+
+import (
+	"testing"
+	"time"
+
+	"github.com/leonhfr/cete/internal/uci"
+	"github.com/notnil/chess"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddGame(t *testing.T) {
+	s := &subscriber{msgs: make(chan []byte, 16), latest: make(chan []byte, 1), kick: func() {}}
+	v := &View{
+		boards:      make(map[string]*Board),
+		subscribers: map[*subscriber]struct{}{s: {}},
+	}
+
+	_, err := v.AddGame("1", "white", "black", chess.StartingPosition())
+	assert.NoError(t, err)
+	b, err := v.AddGame("2", "black", "white", chess.StartingPosition())
+	assert.NoError(t, err)
+	_, err = v.AddGame("1", "white", "black", chess.StartingPosition())
+	assert.Error(t, err)
+
+	assert.NoError(t, b.Finish(chess.Draw, chess.Stalemate))
+
+	fen := chess.StartingPosition().String()
+	assert.Len(t, s.latest, 1)
+	assert.Equal(t, `{"games":[`+
+		`{"id":"1","white":"white","black":"black","position":"`+fen+`"},`+
+		`{"id":"2","white":"black","black":"white","position":"`+fen+`","result":{"outcome":"1/2-1/2","method":"Stalemate"}}`+
+		`]}`, string(<-s.latest))
+
+	got, ok := v.board("2")
+	assert.True(t, ok)
+	assert.Equal(t, b, got)
+	_, ok = v.board("3")
+	assert.False(t, ok)
+}
+
+func TestThink(t *testing.T) {
+	fen := chess.StartingPosition().String()
+	s := &subscriber{msgs: make(chan []byte, 16), latest: make(chan []byte, 1), kick: func() {}}
+	b := &Board{
+		view:        &View{thinkingInterval: time.Hour},
+		position:    chess.StartingPosition(),
+		subscribers: map[*subscriber]struct{}{s: {}},
+	}
+
+	pv := []*chess.Move{move("e2e4", fen), move("e7e5", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")}
+	assert.NoError(t, b.Think(uci.Info{Depth: 1}))
+	assert.NoError(t, b.Think(uci.Info{Depth: 2, Multipv: 2, PV: pv}))
+	assert.NoError(t, b.Think(uci.Info{Depth: 3, Score: uci.Score{CP: 20}, PV: pv}))
+	assert.NoError(t, b.Think(uci.Info{Depth: 4, PV: pv}))
+
+	assert.Len(t, s.msgs, 0)
+	assert.Len(t, s.latest, 1)
+	assert.Equal(t, `{"thinking":{"color":"w","depth":3,"cp":20,"nodes":0,"nps":0,"pv":["e2-e4","e7-e5"],"san":["e4","e5"]}}`, string(<-s.latest))
+}
+
+func TestUpdate(t *testing.T) {
+	fen := "rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq g3 0 2"
+	mate := "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3"
+	v := &View{boards: make(map[string]*Board)}
+	b, err := v.AddGame("1", "white", "black", position(fen))
+	assert.NoError(t, err)
+
+	s := &subscriber{msgs: make(chan []byte, 16), latest: make(chan []byte, 1), kick: func() {}}
+	_, err = b.addSubscriber(s)
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Update(move("d8h4", fen), position(mate), uci.Score{Mate: 1}, 0))
+	assert.Len(t, s.msgs, 1)
+	assert.Contains(t, string(<-s.msgs), `"played":{"san":"Qh4#","position":"`+mate+`"}`)
+
+	msg, err := b.addSubscriber(&subscriber{})
+	assert.NoError(t, err)
+	assert.Contains(t, string(msg), `"history":{"start":"`+fen+`","moves":[{"san":"Qh4#","position":"`+mate+`"}]}`)
+}
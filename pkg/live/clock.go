@@ -0,0 +1,107 @@
+package live
+
+import (
+	"time"
+
+	"github.com/notnil/chess"
+)
+
+// clock is the chess clock of a game played with a fixed time per move.
+//
+// Each side is given the time per move when its clock starts, and the
+// time it uses is accumulated over the game.
+type clock struct {
+	moveTime time.Duration
+	turn     chess.Color
+	started  time.Time
+	white    side
+	black    side
+}
+
+// side is the clock of one side.
+type side struct {
+	remaining time.Duration
+	used      time.Duration
+}
+
+// newClock creates a stopped clock.
+func newClock(moveTime time.Duration) clock {
+	return clock{
+		moveTime: moveTime,
+		turn:     chess.NoColor,
+		white:    side{remaining: moveTime},
+		black:    side{remaining: moveTime},
+	}
+}
+
+// start starts the clock of the side to move.
+func (c *clock) start(turn chess.Color, now time.Time) {
+	c.turn, c.started = turn, now
+	c.side(turn).remaining = c.moveTime
+}
+
+// stop stops the running clock, charging the side to move with the elapsed time.
+func (c *clock) stop(elapsed time.Duration) {
+	if s := c.side(c.turn); s != nil {
+		s.used += elapsed
+		s.remaining = c.moveTime - elapsed
+		if s.remaining < 0 {
+			s.remaining = 0
+		}
+	}
+	c.turn = chess.NoColor
+}
+
+// halt stops the running clock without charging the side to move,
+// e.g. when the game is over.
+func (c *clock) halt() {
+	c.turn = chess.NoColor
+}
+
+// side returns the clock of a side, nil if there is none.
+func (c *clock) side(color chess.Color) *side {
+	switch color {
+	case chess.White:
+		return &c.white
+	case chess.Black:
+		return &c.black
+	case chess.NoColor:
+	}
+	return nil
+}
+
+// state returns the state of the clock at the given time.
+func (c clock) state(now time.Time) clockState {
+	turn := ""
+	if s := c.side(c.turn); s != nil {
+		elapsed := now.Sub(c.started)
+		s.used += elapsed
+		s.remaining -= elapsed
+		if s.remaining < 0 {
+			s.remaining = 0
+		}
+		turn = c.turn.String()
+	}
+
+	return clockState{Turn: turn, White: c.white.state(), Black: c.black.state()}
+}
+
+// clockState is the state of the clock broadcast to the live view.
+// The clock of the side to move ticks in the live view until the next state.
+type clockState struct {
+	// Turn is the side whose clock runs, empty if the clock is stopped.
+	Turn  string    `json:"turn,omitempty"`
+	White sideState `json:"white"`
+	Black sideState `json:"black"`
+}
+
+// sideState is the state of the clock of a side, in milliseconds.
+type sideState struct {
+	Remaining int64 `json:"remaining"`
+	Used      int64 `json:"used"`
+}
+
+// state returns the state of the clock of the side.
+func (s side) state() sideState {
+	return sideState{Remaining: s.remaining.Milliseconds(), Used: s.used.Milliseconds()}
+}
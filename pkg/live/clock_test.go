@@ -0,0 +1,37 @@
+package live
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/notnil/chess"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	now := time.Now()
+	c := newClock(500 * time.Millisecond)
+	assert.Equal(t, clockState{White: sideState{500, 0}, Black: sideState{500, 0}}, c.state(now))
+
+	c.start(chess.White, now)
+	assert.Equal(t, clockState{Turn: "w", White: sideState{300, 200}, Black: sideState{500, 0}}, c.state(now.Add(200*time.Millisecond)))
+	assert.Equal(t, clockState{Turn: "w", White: sideState{0, 700}, Black: sideState{500, 0}}, c.state(now.Add(700*time.Millisecond)))
+
+	c.stop(450 * time.Millisecond)
+	c.start(chess.Black, now)
+	assert.Equal(t, clockState{Turn: "b", White: sideState{50, 450}, Black: sideState{400, 100}}, c.state(now.Add(100*time.Millisecond)))
+
+	c.stop(300 * time.Millisecond)
+	c.start(chess.White, now)
+	assert.Equal(t, clockState{Turn: "w", White: sideState{500, 450}, Black: sideState{200, 300}}, c.state(now))
+
+	c.stop(600 * time.Millisecond)
+	assert.Equal(t, clockState{White: sideState{0, 1050}, Black: sideState{200, 300}}, c.state(now.Add(time.Second)))
+}
+
+func TestClockStateMarshalJSON(t *testing.T) {
+	msg, err := json.Marshal(clockState{Turn: "b", White: sideState{50, 450}, Black: sideState{400, 100}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"turn":"b","white":{"remaining":50,"used":450},"black":{"remaining":400,"used":100}}`, string(msg))
+}
@@ -3,69 +3,130 @@ package live
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/leonhfr/cete/internal/uci"
 	"github.com/leonhfr/cete/static"
 	"github.com/notnil/chess"
 	"nhooyr.io/websocket"
 )
 
-// View represents a live web view.
+// View represents a live web view broadcasting the games being played.
 type View struct {
+	boards                  map[string]*Board
+	config                  Config
+	ids                     []string
 	logger                  *log.Logger
 	mu                      sync.Mutex
-	port                    int
-	position                *chess.Position
+	resumed                 chan struct{}
 	serveMux                http.ServeMux
 	shutdown                func() error
+	standings               json.RawMessage
 	subscribeMessageBuffer  int
 	subscribeMessageLimiter time.Duration
 	subscribers             map[*subscriber]struct{}
+	thinkingInterval        time.Duration
 	wait                    chan struct{}
 }
 
+// Config is the configuration of a live view.
+type Config struct {
+	// Address is the host the view listens on, all interfaces if empty.
+	Address string
+	Port    int
+	// TLSCert and TLSKey are the files of the certificate and its key
+	// the view is served with over HTTPS, plain HTTP being used if empty.
+	TLSCert string
+	TLSKey  string
+	// User and Password protect the control endpoints with basic
+	// authentication, and Token with a bearer token. Either is accepted
+	// when both are set, and the endpoints are open if none is.
+	User     string
+	Password string
+	Token    string
+	// Private protects spectating as well as the control endpoints.
+	Private bool
+	// Autostart starts the games without waiting for the user
+	// to press the start button, after the start delay.
+	Autostart  bool
+	StartDelay time.Duration
+}
+
 type subscriber struct {
 	msgs chan []byte
-	kick func()
+	// latest holds the latest of the messages superseding the previous ones,
+	// such as the thinking of the engines, sent as soon as possible since
+	// unlike moves they are not animated in the live view.
+	latest chan []byte
+	kick   func()
 }
 
 // New creates a new live view.
-func New(port int, logger *log.Logger) (*View, chan error, error) {
+func New(config Config, logger *log.Logger) (*View, chan error, error) {
 	errc := make(chan error, 1)
-	l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err := config.validate(); err != nil {
+		return nil, errc, err
+	}
+
+	// the key pair is loaded before listening so that a bad certificate
+	// is reported right away instead of once the server is serving
+	var tlsConfig *tls.Config
+	if config.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCert, config.TLSKey)
+		if err != nil {
+			return nil, errc, fmt.Errorf("live: %w", err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	l, err := net.Listen("tcp", net.JoinHostPort(config.Address, strconv.Itoa(config.Port)))
 	if err != nil {
 		return nil, errc, err
 	}
 
 	view := &View{
+		boards:                  make(map[string]*Board),
+		config:                  config,
 		logger:                  logger,
-		port:                    port,
-		position:                chess.StartingPosition(),
 		subscribeMessageBuffer:  16,
 		subscribeMessageLimiter: 200 * time.Millisecond,
 		subscribers:             make(map[*subscriber]struct{}),
+		thinkingInterval:        500 * time.Millisecond,
 		wait:                    make(chan struct{}),
 	}
 
-	view.serveMux.Handle("/", http.FileServer(http.FS(static.FileSystem)))
-	view.serveMux.HandleFunc("/start", view.startHandler)
-	view.serveMux.HandleFunc("/subscribe", view.subscribeHandler)
+	view.serveMux.Handle("/", view.spectate(http.FileServer(http.FS(static.FileSystem))))
+	view.serveMux.Handle("/start", view.authorize(http.HandlerFunc(view.startHandler)))
+	view.serveMux.Handle("/pause", view.authorize(http.HandlerFunc(view.pauseHandler)))
+	view.serveMux.Handle("/resume", view.authorize(http.HandlerFunc(view.resumeHandler)))
+	view.serveMux.Handle("/subscribe", view.spectate(http.HandlerFunc(view.subscribeHandler)))
+	view.serveMux.Handle("/api/games", view.spectate(http.HandlerFunc(view.apiGamesHandler)))
+	view.serveMux.Handle("/api/games/", view.spectate(http.HandlerFunc(view.apiGameHandler)))
+	view.serveMux.Handle("/api/standings", view.spectate(http.HandlerFunc(view.apiStandingsHandler)))
 
 	server := &http.Server{
 		Handler:      view,
+		ErrorLog:     logger,
+		TLSConfig:    tlsConfig,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
 
 	go func() {
 		defer close(errc)
+		if tlsConfig != nil {
+			errc <- server.ServeTLS(l, "", "")
+			return
+		}
 		errc <- server.Serve(l)
 	}()
 
@@ -81,9 +142,27 @@ func New(port int, logger *log.Logger) (*View, chan error, error) {
 	return view, errc, nil
 }
 
-// Wait awaits that the user confirms the view is live.
+// Wait awaits that the user confirms the view is live,
+// or the start delay if the view starts automatically.
 func (v *View) Wait(ctx context.Context) {
-	v.logger.Printf("live view on http://localhost:%d\n", v.port)
+	v.logger.Printf("live view on %s\n", v.config.url())
+
+	if v.config.Autostart {
+		if v.config.StartDelay > 0 {
+			v.logger.Printf("starting in %s\n", v.config.StartDelay)
+		}
+
+		timer := time.NewTimer(v.config.StartDelay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-v.wait:
+		case <-ctx.Done():
+		}
+		return
+	}
+
 	v.logger.Printf("press start to continue\n")
 	select {
 	case <-v.wait:
@@ -91,26 +170,107 @@ func (v *View) Wait(ctx context.Context) {
 	}
 }
 
-// Update updates the live view with the latest move and position.
-func (v *View) Update(move *chess.Move, position *chess.Position) error {
+// paused returns the channel closed when the view is resumed,
+// nil if the view is not paused.
+func (v *View) paused() <-chan struct{} {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.resumed
+}
+
+// AddGame adds a game to the live view, listed in its index
+// and broadcast on its own board.
+func (v *View) AddGame(id, white, black string, position *chess.Position) (*Board, error) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
-	v.position = position
-	msg, err := json.Marshal(&message{Move: move, Position: position})
+	if _, ok := v.boards[id]; ok {
+		return nil, fmt.Errorf("live: game %s already exists", id)
+	}
+
+	b := &Board{
+		id:          id,
+		white:       white,
+		black:       black,
+		view:        v,
+		clock:       newClock(0),
+		history:     history{Start: position.String(), Moves: []historyMove{}},
+		position:    position,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+	v.boards[id] = b
+	v.ids = append(v.ids, id)
+
+	return b, v.broadcastIndex()
+}
+
+// SetStandings sets the standings of the match being played, shown on
+// the standings page of the live view. They are marshalled to JSON and
+// broadcast along with the index of the games.
+func (v *View) SetStandings(standings interface{}) error {
+	raw, err := json.Marshal(standings)
 	if err != nil {
 		return err
 	}
 
-	for s := range v.subscribers {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.standings = raw
+	return v.broadcastIndex()
+}
+
+// index returns the message listing the games, along with the standings
+// if any. It must be called with the mutex locked.
+func (v *View) index() ([]byte, error) {
+	return json.Marshal(&indexMessage{Games: v.summaries(), Standings: v.standings, Paused: v.resumed != nil})
+}
+
+// summaries returns the summaries of the games.
+// It must be called with the mutex locked.
+func (v *View) summaries() []summary {
+	games := make([]summary, 0, len(v.ids))
+	for _, id := range v.ids {
+		games = append(games, v.boards[id].summary())
+	}
+	return games
+}
+
+// broadcastIndex sends the list of the games to the subscribers
+// of the index. It must be called with the mutex locked.
+func (v *View) broadcastIndex() error {
+	msg, err := v.index()
+	if err != nil {
+		return err
+	}
+
+	broadcastLatest(v.subscribers, msg)
+	return nil
+}
+
+// broadcast sends a message to the subscribers, kicking the ones
+// too slow to keep up. It must be called with the mutex locked.
+func broadcast(subscribers map[*subscriber]struct{}, msg []byte) {
+	for s := range subscribers {
 		select {
 		case s.msgs <- msg:
 		default:
 			go s.kick()
 		}
 	}
+}
 
-	return nil
+// broadcastLatest sends a message superseding the previous ones to the
+// subscribers, replacing the previous one if not sent yet.
+// It must be called with the mutex locked.
+func broadcastLatest(subscribers map[*subscriber]struct{}, msg []byte) {
+	for s := range subscribers {
+		select {
+		case <-s.latest:
+		default:
+		}
+		s.latest <- msg
+	}
 }
 
 // Shutdown shuts down the live view gracefully.
@@ -140,9 +300,62 @@ func (v *View) startHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// pauseHandler pauses the games before their next move
+func (v *View) pauseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	defer w.WriteHeader(http.StatusAccepted)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.resumed == nil {
+		v.resumed = make(chan struct{})
+		v.logger.Printf("paused\n")
+		if err := v.broadcastIndex(); err != nil {
+			v.logger.Printf("%v", err)
+		}
+	}
+}
+
+// resumeHandler resumes the paused games
+func (v *View) resumeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	defer w.WriteHeader(http.StatusAccepted)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.resumed != nil {
+		close(v.resumed)
+		v.resumed = nil
+		v.logger.Printf("resumed\n")
+		if err := v.broadcastIndex(); err != nil {
+			v.logger.Printf("%v", err)
+		}
+	}
+}
+
 // subscribeHandler accepts the WebSocket connection
-// sends the current game state and subscribes it to all future messages
+// sends the current state and subscribes it to all future messages,
+// of the game whose id is passed in the game query parameter
+// or of the index of the games otherwise
 func (v *View) subscribeHandler(w http.ResponseWriter, r *http.Request) {
+	add, remove := v.addSubscriber, v.deleteSubscriber
+	if id := r.URL.Query().Get("game"); id != "" {
+		b, ok := v.board(id)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		add, remove = b.addSubscriber, b.deleteSubscriber
+	}
+
 	c, err := websocket.Accept(w, r, nil)
 	if err != nil {
 		v.logger.Printf("%v", err)
@@ -150,7 +363,7 @@ func (v *View) subscribeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer c.Close(websocket.StatusInternalError, "")
 
-	err = v.subscribe(r.Context(), c)
+	err = v.subscribe(r.Context(), c, add, remove)
 	if errors.Is(err, context.Canceled) {
 		return
 	}
@@ -167,24 +380,27 @@ func (v *View) subscribeHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // subscribe subscribes the given WebSocket to all broadcasted messages
-func (v *View) subscribe(ctx context.Context, c *websocket.Conn) error {
+//
+// The add function registers the subscriber and returns the message setting
+// the current state, sent before any other message.
+func (v *View) subscribe(ctx context.Context, c *websocket.Conn, add func(*subscriber) ([]byte, error), remove func(*subscriber)) error {
 	ctx = c.CloseRead(ctx)
 
 	s := &subscriber{
-		msgs: make(chan []byte, v.subscribeMessageBuffer),
+		msgs:   make(chan []byte, v.subscribeMessageBuffer),
+		latest: make(chan []byte, 1),
 		kick: func() {
 			c.Close(websocket.StatusPolicyViolation, "connection too slow to keep up with messages")
 		},
 	}
-	v.addSubscriber(s)
-	defer v.deleteSubscriber(s)
-
-	// first message sets the position
-	msg, err := json.Marshal(&message{Position: v.position})
+	msg, err := add(s)
+	defer remove(s)
 	if err != nil {
 		return err
 	}
-	s.msgs <- msg
+	if err := writeTimeout(ctx, time.Second, c, msg); err != nil {
+		return err
+	}
 
 	limiter := time.NewTicker(v.subscribeMessageLimiter)
 	for {
@@ -198,20 +414,39 @@ func (v *View) subscribe(ctx context.Context, c *websocket.Conn) error {
 				}
 			default:
 			}
+		case msg := <-s.latest:
+			if len(s.msgs) > 0 {
+				// stale, the move it precedes has not been sent yet
+				continue
+			}
+			err := writeTimeout(ctx, time.Second, c, msg)
+			if err != nil {
+				return err
+			}
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
 }
 
-// addSubscriber adds a subscriber
-func (v *View) addSubscriber(s *subscriber) {
+// board returns the board of a game
+func (v *View) board(id string) (*Board, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	b, ok := v.boards[id]
+	return b, ok
+}
+
+// addSubscriber adds a subscriber to the index of the games
+// and returns the message listing them
+func (v *View) addSubscriber(s *subscriber) ([]byte, error) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 	v.subscribers[s] = struct{}{}
+	return v.index()
 }
 
-// deleteSubscriber deletes a subscriber
+// deleteSubscriber deletes a subscriber from the index of the games
 func (v *View) deleteSubscriber(s *subscriber) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
@@ -229,6 +464,149 @@ func writeTimeout(ctx context.Context, timeout time.Duration, c *websocket.Conn,
 type message struct {
 	Move     *chess.Move
 	Position *chess.Position
+	// Evaluation is the score of the move.
+	Evaluation *evaluation
+	// Evaluations are the scores of all the moves played,
+	// sent along with the position.
+	Evaluations []evaluation
+	// Thinking is the search of the engine to move.
+	Thinking *thinking
+	// Clock is the state of the clock.
+	Clock *clockState
+	// Played is the move in algebraic notation along with the position
+	// it leads to, sent along with the move to extend the history.
+	Played *historyMove
+	// History is the history of the game, sent along with the position
+	// so that a spectator joining mid-game can replay it.
+	History *history
+	// Players are the names of the engines, sent along with the position.
+	Players *players
+	// Result is the result of the game once over.
+	Result *result
+}
+
+// history is the history of a game.
+type history struct {
+	// Start is the FEN of the starting position.
+	Start string        `json:"start"`
+	Moves []historyMove `json:"moves"`
+}
+
+// historyMove is a move of the history of a game.
+type historyMove struct {
+	SAN string `json:"san"`
+	// Position is the FEN of the position the move leads to.
+	Position string `json:"position"`
+}
+
+// newHistoryMove creates the history move of a move played in a position,
+// leading to the next position.
+func newHistoryMove(position *chess.Position, m *chess.Move, next *chess.Position) historyMove {
+	san := m.String()
+	if move := validMove(position, m); move != nil {
+		san = chess.AlgebraicNotation{}.Encode(position, move)
+	}
+	return historyMove{SAN: san, Position: next.String()}
+}
+
+// players are the names of the engines playing a game.
+type players struct {
+	White string `json:"white"`
+	Black string `json:"black"`
+}
+
+// result is the result of a finished game.
+type result struct {
+	Outcome string `json:"outcome"`
+	Method  string `json:"method"`
+}
+
+// summary is the summary of a game listed in the index of the live view.
+type summary struct {
+	ID       string      `json:"id"`
+	White    string      `json:"white"`
+	Black    string      `json:"black"`
+	Position string      `json:"position"`
+	Last     *evaluation `json:"evaluation,omitempty"`
+	Result   *result     `json:"result,omitempty"`
+}
+
+// indexMessage is broadcast between cete and the index of the live view
+// whenever a game is added or updated, or the standings change.
+type indexMessage struct {
+	Games     []summary       `json:"games"`
+	Standings json.RawMessage `json:"standings,omitempty"`
+	Paused    bool            `json:"paused,omitempty"`
+}
+
+// evaluation is the score of a move from the point of view
+// of the engine that played it.
+type evaluation struct {
+	Ply   int    `json:"ply"`
+	Color string `json:"color"`
+	CP    int    `json:"cp"`
+	Mate  int    `json:"mate,omitempty"`
+}
+
+// thinking is the state of the search of the engine to move.
+type thinking struct {
+	Color    string `json:"color"`
+	Depth    int    `json:"depth"`
+	Seldepth int    `json:"seldepth,omitempty"`
+	CP       int    `json:"cp"`
+	Mate     int    `json:"mate,omitempty"`
+	Nodes    int64  `json:"nodes"`
+	NPS      int64  `json:"nps"`
+	// PV are the moves of the principal variation, as from-to squares.
+	PV []string `json:"pv"`
+	// SAN are the moves of the principal variation in algebraic notation.
+	SAN []string `json:"san"`
+}
+
+// newThinking creates the state of a search from an info line
+// of the engine searching the position.
+func newThinking(position *chess.Position, info uci.Info) thinking {
+	t := thinking{
+		Color:    position.Turn().String(),
+		Depth:    info.Depth,
+		Seldepth: info.Seldepth,
+		CP:       info.Score.CP,
+		Mate:     info.Score.Mate,
+		Nodes:    info.Nodes,
+		NPS:      info.NPS,
+		PV:       []string{},
+		SAN:      []string{},
+	}
+
+	pos := position
+	for _, m := range info.PV {
+		move := validMove(pos, m)
+		if move == nil {
+			break
+		}
+
+		t.PV = append(t.PV, fmt.Sprintf("%v-%v", move.S1(), move.S2()))
+		t.SAN = append(t.SAN, chess.AlgebraicNotation{}.Encode(pos, move))
+		pos = pos.Update(move)
+	}
+
+	return t
+}
+
+// validMove returns the valid move of the position matching the move,
+// which holds all tags needed for the algebraic notation, or nil.
+func validMove(position *chess.Position, m *chess.Move) *chess.Move {
+	for _, move := range position.ValidMoves() {
+		if move.S1() == m.S1() && move.S2() == m.S2() && move.Promo() == m.Promo() {
+			return move
+		}
+	}
+	return nil
+}
+
+// newEvaluation creates an evaluation of the move played at the given ply.
+func newEvaluation(ply int, color chess.Color, score uci.Score) evaluation {
+	return evaluation{Ply: ply, Color: color.String(), CP: score.CP, Mate: score.Mate}
 }
 
 // MarshalJSON implements the encoding/json.Marshaler interface.
@@ -265,12 +643,28 @@ func (m *message) MarshalJSON() ([]byte, error) {
 	}
 
 	return json.Marshal(&struct {
-		Move         string `json:"move,omitempty"`
-		CastlingMove string `json:"castlingMove,omitempty"`
-		Position     string `json:"position,omitempty"`
+		Move         string       `json:"move,omitempty"`
+		CastlingMove string       `json:"castlingMove,omitempty"`
+		Position     string       `json:"position,omitempty"`
+		Evaluation   *evaluation  `json:"evaluation,omitempty"`
+		Evaluations  []evaluation `json:"evaluations,omitempty"`
+		Thinking     *thinking    `json:"thinking,omitempty"`
+		Clock        *clockState  `json:"clock,omitempty"`
+		Played       *historyMove `json:"played,omitempty"`
+		History      *history     `json:"history,omitempty"`
+		Players      *players     `json:"players,omitempty"`
+		Result       *result      `json:"result,omitempty"`
 	}{
 		Move:         move,
 		CastlingMove: castlingMove,
 		Position:     position,
+		Evaluation:   m.Evaluation,
+		Evaluations:  m.Evaluations,
+		Thinking:     m.Thinking,
+		Clock:        m.Clock,
+		Played:       m.Played,
+		History:      m.History,
+		Players:      m.Players,
+		Result:       m.Result,
 	})
 }
@@ -1,11 +1,21 @@
 package live
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/leonhfr/cete/internal/uci"
 	"github.com/notnil/chess"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMessageMarshalJSON(t *testing.T) {
@@ -22,7 +32,7 @@ func TestMessageMarshalJSON(t *testing.T) {
 	}{
 		{
 			"single move",
-			message{move("b1a3", fen0), chess.StartingPosition()},
+			message{Move: move("b1a3", fen0), Position: chess.StartingPosition()},
 			`{"move":"b1-a3"}`,
 		},
 		{
@@ -30,6 +40,26 @@ func TestMessageMarshalJSON(t *testing.T) {
 			message{Position: position(fen1)},
 			`{"position":"` + fen1 + `"}`,
 		},
+		{
+			"evaluation",
+			message{Move: move("b1a3", fen0), Position: chess.StartingPosition(), Evaluation: &evaluation{Ply: 1, Color: "w", CP: -35}},
+			`{"move":"b1-a3","evaluation":{"ply":1,"color":"w","cp":-35}}`,
+		},
+		{
+			"set position with evaluations",
+			message{Position: position(fen1), Evaluations: []evaluation{{Ply: 1, Color: "w", CP: 20}, {Ply: 2, Color: "b", Mate: -3}}},
+			`{"position":"` + fen1 + `","evaluations":[{"ply":1,"color":"w","cp":20},{"ply":2,"color":"b","cp":0,"mate":-3}]}`,
+		},
+		{
+			"played move",
+			message{Move: move("b1a3", fen0), Position: chess.StartingPosition(), Played: &historyMove{SAN: "Na3", Position: fen1}},
+			`{"move":"b1-a3","played":{"san":"Na3","position":"` + fen1 + `"}}`,
+		},
+		{
+			"set position with history",
+			message{Position: position(fen1), History: &history{Start: fen0, Moves: []historyMove{{SAN: "Na3", Position: fen1}}}},
+			`{"position":"` + fen1 + `","history":{"start":"` + fen0 + `","moves":[{"san":"Na3","position":"` + fen1 + `"}]}}`,
+		},
 		{
 			"promotion",
 			message{Move: move("e2e1n", fen2), Position: position(fen2)},
@@ -77,3 +107,107 @@ func move(uci, fen string) *chess.Move {
 	m, _ := chess.UCINotation{}.Decode(p, uci)
 	return m
 }
+
+func TestNewThinking(t *testing.T) {
+	fen := "rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq - 0 2"
+	pos := position(fen)
+	info := uci.Info{
+		Depth:    12,
+		Seldepth: 14,
+		Score:    uci.Score{Mate: 1},
+		Nodes:    1000,
+		NPS:      5000,
+		PV:       []*chess.Move{move("d8h4", fen)},
+	}
+
+	assert.Equal(t, thinking{
+		Color:    "b",
+		Depth:    12,
+		Seldepth: 14,
+		Mate:     1,
+		Nodes:    1000,
+		NPS:      5000,
+		PV:       []string{"d8-h4"},
+		SAN:      []string{"Qh4#"},
+	}, newThinking(pos, info))
+}
+
+func TestSetStandings(t *testing.T) {
+	s := &subscriber{msgs: make(chan []byte, 16), latest: make(chan []byte, 1), kick: func() {}}
+	v := &View{
+		boards:      make(map[string]*Board),
+		subscribers: map[*subscriber]struct{}{s: {}},
+	}
+
+	assert.NoError(t, v.SetStandings(map[string]int{"points": 1}))
+	assert.Equal(t, `{"games":[],"standings":{"points":1}}`, string(<-s.latest))
+
+	msg, err := v.addSubscriber(&subscriber{})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"games":[],"standings":{"points":1}}`, string(msg))
+}
+
+func TestPauseResume(t *testing.T) {
+	v, _, err := New(Config{Autostart: true}, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+	defer func() { _ = v.Shutdown() }()
+
+	ctx := context.Background()
+	v.Wait(ctx)
+
+	b, err := v.AddGame("1", "white", "black", chess.StartingPosition())
+	require.NoError(t, err)
+	require.NoError(t, b.Wait(ctx))
+
+	post := func(path string) int {
+		rec := httptest.NewRecorder()
+		v.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusAccepted, post("/pause"))
+	assert.Equal(t, http.StatusAccepted, post("/pause"))
+
+	msg, err := v.index()
+	require.NoError(t, err)
+	assert.Contains(t, string(msg), `"paused":true`)
+
+	done := make(chan error)
+	go func() { done <- b.Wait(ctx) }()
+
+	select {
+	case <-done:
+		t.Fatal("expected the board to wait while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, http.StatusAccepted, post("/resume"))
+	assert.NoError(t, <-done)
+
+	rec := httptest.NewRecorder()
+	v.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pause", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestNewTLS(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a certificate"), 0o600))
+
+	tests := []struct {
+		name string
+		cert string
+		key  string
+	}{
+		{"missing files", filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")},
+		{"invalid files", invalid, invalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, _, err := New(Config{TLSCert: tt.cert, TLSKey: tt.key}, log.New(io.Discard, "", 0))
+			assert.Error(t, err)
+			assert.Nil(t, v)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package match
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/leonhfr/cete/pkg/game"
+	"github.com/notnil/chess"
+)
+
+// Journal is the append-only file of a match, holding the match and its
+// schedule followed by each finished game, one JSON object per line.
+//
+// A match is resumed by opening its journal and playing the pending games.
+//
+// The match is recorded as configured, including the environment variables
+// of the players which may hold secrets, so the journal is created readable
+// by its owner only.
+type Journal struct {
+	Match    Match
+	Schedule []Pairing
+	// Games are the records of the finished games by index in the schedule.
+	Games map[int]game.Record
+
+	file *os.File
+}
+
+type (
+	// journalHeader is the first line of a journal.
+	journalHeader struct {
+		Match    Match     `json:"match"`
+		Schedule []Pairing `json:"schedule"`
+	}
+
+	// journalEntry is a line of a journal recording a finished game.
+	journalEntry struct {
+		Game   int         `json:"game"`
+		Record game.Record `json:"record"`
+	}
+)
+
+// Create creates the journal of a new match. It fails if the file exists.
+func Create(filename string, m Match) (*Journal, error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{Match: m, Schedule: m.Schedule(), Games: map[int]game.Record{}, file: f}
+	if err := j.write(journalHeader{Match: m, Schedule: j.Schedule}); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Open opens the journal of a match to resume it.
+//
+// A last line that was not entirely written, e.g. if cete was killed,
+// is discarded.
+func Open(filename string) (*Journal, error) {
+	f, err := os.OpenFile(filename, os.O_RDWR, 0) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	j, size, err := read(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	if err := f.Truncate(size); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	j.file = f
+	return j, nil
+}
+
+// read reads a journal and returns the size of its complete lines.
+func read(r io.Reader) (*Journal, int64, error) {
+	reader := bufio.NewReader(r)
+
+	var size int64
+	var j *Journal
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, 0, err
+		}
+
+		if j == nil {
+			var header journalHeader
+			if err := json.Unmarshal(line, &header); err != nil {
+				return nil, 0, fmt.Errorf("line %d: %w", n, err)
+			}
+			j = &Journal{Match: header.Match, Schedule: header.Schedule, Games: map[int]game.Record{}}
+		} else {
+			var entry journalEntry
+			if err := json.Unmarshal(line, &entry); err != nil {
+				return nil, 0, fmt.Errorf("line %d: %w", n, err)
+			}
+			if entry.Game < 0 || entry.Game >= len(j.Schedule) {
+				return nil, 0, fmt.Errorf("line %d: game %d is not scheduled", n, entry.Game)
+			}
+			j.Games[entry.Game] = entry.Record
+		}
+
+		size += int64(len(line))
+	}
+
+	if j == nil {
+		return nil, 0, errors.New("empty journal")
+	}
+
+	return j, size, nil
+}
+
+// Append records a finished game and syncs the journal to disk.
+func (j *Journal) Append(n int, record game.Record) error {
+	if err := j.write(journalEntry{Game: n, Record: record}); err != nil {
+		return err
+	}
+
+	j.Games[n] = record
+	return nil
+}
+
+// Pending returns the indexes in the schedule of the games left to play.
+func (j *Journal) Pending() []int {
+	pending := []int{}
+	for n := range j.Schedule {
+		if _, ok := j.Games[n]; !ok {
+			pending = append(pending, n)
+		}
+	}
+	return pending
+}
+
+// Score returns the score of the first player against the second one
+// in the finished games.
+func (j *Journal) Score(first, second int) game.Score {
+	score := game.Score{}
+	for n := range j.Schedule {
+		record, ok := j.Games[n]
+		if !ok {
+			continue
+		}
+
+		switch p := j.Schedule[n]; {
+		case p.White == first && p.Black == second:
+			score.Add(chess.Outcome(record.Result), true)
+		case p.White == second && p.Black == first:
+			score.Add(chess.Outcome(record.Result), false)
+		}
+	}
+	return score
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// write writes a line to the journal and syncs it to disk.
+func (j *Journal) write(v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return j.file.Sync()
+}
@@ -0,0 +1,88 @@
+package match
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leonhfr/cete/pkg/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cete.journal")
+	m := Match{Players: []game.Player{{Engine: "a"}, {Engine: "b"}}, Games: 4, Seed: 1, PGNOut: "games.pgn"}
+
+	j, err := Create(filename, m)
+	require.NoError(t, err)
+	require.NoError(t, j.Append(0, game.Record{Result: "1-0"}))
+	require.NoError(t, j.Append(1, game.Record{Result: "1-0"}))
+	require.NoError(t, j.Close())
+
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	_, err = Create(filename, m)
+	assert.ErrorIs(t, err, os.ErrExist)
+
+	j, err = Open(filename)
+	require.NoError(t, err)
+	assert.Equal(t, m, j.Match)
+	assert.Equal(t, m.Schedule(), j.Schedule)
+	assert.Equal(t, []int{2, 3}, j.Pending())
+	assert.Equal(t, game.Score{Wins: 1, Losses: 1}, j.Score(0, 1))
+	assert.Equal(t, game.Score{Wins: 1, Losses: 1}, j.Score(1, 0))
+
+	require.NoError(t, j.Append(2, game.Record{Result: "1/2-1/2"}))
+	require.NoError(t, j.Close())
+
+	j, err = Open(filename)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, j.Pending())
+	assert.Equal(t, game.Score{Wins: 1, Losses: 1, Draws: 1}, j.Score(0, 1))
+	require.NoError(t, j.Close())
+}
+
+func TestJournalTruncated(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "cete.journal")
+	m := Match{Players: []game.Player{{Engine: "a"}, {Engine: "b"}}, Games: 2}
+
+	j, err := Create(filename, m)
+	require.NoError(t, err)
+	require.NoError(t, j.Append(0, game.Record{Result: "0-1"}))
+	require.NoError(t, j.Close())
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"game":1,"rec`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	j, err = Open(filename)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, j.Pending())
+	require.NoError(t, j.Append(1, game.Record{Result: "0-1"}))
+	require.NoError(t, j.Close())
+
+	j, err = Open(filename)
+	require.NoError(t, err)
+	assert.Empty(t, j.Pending())
+	assert.Equal(t, game.Score{Wins: 1, Losses: 1}, j.Score(0, 1))
+	require.NoError(t, j.Close())
+}
+
+func TestOpenError(t *testing.T) {
+	dir := t.TempDir()
+
+	empty := filepath.Join(dir, "empty.journal")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+	_, err := Open(empty)
+	assert.Error(t, err)
+
+	invalid := filepath.Join(dir, "invalid.journal")
+	require.NoError(t, os.WriteFile(invalid, []byte("{\"match\":{}}\n{\"game\":3}\n"), 0o600))
+	_, err = Open(invalid)
+	assert.Error(t, err)
+}
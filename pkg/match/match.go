@@ -0,0 +1,147 @@
+// Package match schedules series of games between engines
+// and journals them so that interrupted matches can be resumed.
+package match
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/leonhfr/cete/pkg/game"
+	"github.com/notnil/chess"
+)
+
+// Match is a series of games in which every player meets every other player.
+type Match struct {
+	Players []game.Player `json:"players"`
+	// Games is the number of games played by each pair of players,
+	// colors alternating between games.
+	Games int `json:"games"`
+	// Time is the time per move.
+	Time time.Duration `json:"time"`
+	// Openings are the FEN of the starting positions, each being played
+	// with both colors. The standard starting position is used if empty.
+	Openings []string `json:"openings,omitempty"`
+	// Seed is the seed the openings are shuffled with.
+	Seed int64 `json:"seed"`
+	// PGNOut is the PGN file the finished games are appended to, if any.
+	PGNOut string `json:"pgn_out,omitempty"`
+}
+
+// Pairing is a game of the schedule.
+type Pairing struct {
+	// White and Black are the indexes of the players.
+	White int `json:"white"`
+	Black int `json:"black"`
+	// Opening is the FEN of the starting position,
+	// the standard starting position if empty.
+	Opening string `json:"opening,omitempty"`
+}
+
+// Schedule returns the games of the match in the order they are played.
+//
+// The schedule only depends on the match, so that a match
+// always plays the same games in the same order.
+func (m Match) Schedule() []Pairing {
+	openings := append([]string(nil), m.Openings...)
+	r := rand.New(rand.NewSource(m.Seed)) //nolint:gosec // reproducible on purpose
+	r.Shuffle(len(openings), func(i, j int) {
+		openings[i], openings[j] = openings[j], openings[i]
+	})
+
+	schedule := []Pairing{}
+	for g := 0; g < m.Games; g++ {
+		var opening string
+		if len(openings) > 0 {
+			opening = openings[(g/2)%len(openings)]
+		}
+
+		for i := range m.Players {
+			for j := i + 1; j < len(m.Players); j++ {
+				if g%2 == 0 {
+					schedule = append(schedule, Pairing{White: i, Black: j, Opening: opening})
+				} else {
+					schedule = append(schedule, Pairing{White: j, Black: i, Opening: opening})
+				}
+			}
+		}
+	}
+
+	return schedule
+}
+
+// Input returns the input of a scheduled game.
+func (m Match) Input(p Pairing) game.Input {
+	return game.Input{
+		White:   m.Players[p.White],
+		Black:   m.Players[p.Black],
+		Time:    m.Time,
+		Opening: p.Opening,
+	}
+}
+
+// Names returns the names of the players, the base name of their engine
+// followed by their position in the match when several players share it.
+func (m Match) Names() []string {
+	count := make(map[string]int, len(m.Players))
+	for _, p := range m.Players {
+		count[path.Base(p.Engine)]++
+	}
+
+	names := make([]string, 0, len(m.Players))
+	for i, p := range m.Players {
+		name := path.Base(p.Engine)
+		if count[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, i+1)
+		}
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// ParseOpenings parses starting positions, one per line, either in FEN
+// or in EPD. Empty lines and lines starting with # are ignored.
+func ParseOpenings(r io.Reader) ([]string, error) {
+	openings := []string{}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("line %d: invalid position %q", n, line)
+		}
+
+		fen := strings.Join(fields[:4], " ") + " 0 1"
+		if len(fields) >= 6 && isNumber(fields[4]) && isNumber(fields[5]) {
+			fen = strings.Join(fields[:6], " ")
+		}
+
+		if _, err := chess.FEN(fen); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+
+		openings = append(openings, fen)
+	}
+
+	return openings, scanner.Err()
+}
+
+// isNumber reports whether s only holds digits.
+func isNumber(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
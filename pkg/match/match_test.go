@@ -0,0 +1,99 @@
+package match
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leonhfr/cete/pkg/game"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	players := []game.Player{{Engine: "a"}, {Engine: "b"}, {Engine: "c"}}
+
+	tests := []struct {
+		name  string
+		match Match
+		want  []Pairing
+	}{
+		{
+			"two players",
+			Match{Players: players[:2], Games: 3},
+			[]Pairing{{0, 1, ""}, {1, 0, ""}, {0, 1, ""}},
+		},
+		{
+			"three players",
+			Match{Players: players, Games: 2},
+			[]Pairing{{0, 1, ""}, {0, 2, ""}, {1, 2, ""}, {1, 0, ""}, {2, 0, ""}, {2, 1, ""}},
+		},
+		{
+			"openings",
+			Match{Players: players[:2], Games: 4, Openings: []string{"fen"}},
+			[]Pairing{{0, 1, "fen"}, {1, 0, "fen"}, {0, 1, "fen"}, {1, 0, "fen"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.match.Schedule())
+		})
+	}
+}
+
+func TestScheduleSeed(t *testing.T) {
+	m := Match{
+		Players:  []game.Player{{Engine: "a"}, {Engine: "b"}},
+		Games:    8,
+		Openings: []string{"1", "2", "3", "4"},
+		Seed:     42,
+	}
+
+	schedule := m.Schedule()
+	assert.Equal(t, schedule, m.Schedule())
+
+	openings := map[string]int{}
+	for i, p := range schedule {
+		openings[p.Opening]++
+		if i%2 == 1 {
+			assert.Equal(t, schedule[i-1].Opening, p.Opening)
+			assert.Equal(t, schedule[i-1].White, p.Black)
+		}
+	}
+	assert.Equal(t, map[string]int{"1": 2, "2": 2, "3": 2, "4": 2}, openings)
+}
+
+func TestNames(t *testing.T) {
+	m := Match{Players: []game.Player{{Engine: "/engines/stockfish"}, {Engine: "lc0"}, {Engine: "stockfish"}}}
+	assert.Equal(t, []string{"stockfish#1", "lc0", "stockfish#3"}, m.Names())
+}
+
+func TestParseOpenings(t *testing.T) {
+	input := `# openings
+rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 id "e4";
+
+rnbqkbnr/pppppppp/8/8/3P4/8/PPP1PPPP/RNBQKBNR b KQkq d3 0 1
+rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2
+`
+	openings, err := ParseOpenings(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"rnbqkbnr/pppppppp/8/8/3P4/8/PPP1PPPP/RNBQKBNR b KQkq d3 0 1",
+		"rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2",
+	}, openings)
+}
+
+func TestParseOpeningsError(t *testing.T) {
+	tests := []string{
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b",
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNX b KQkq -",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			_, err := ParseOpenings(strings.NewReader(tt))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package match
+
+import (
+	"math"
+	"sort"
+
+	"github.com/notnil/chess"
+)
+
+// Standings are the standings of a match, best players first.
+type Standings struct {
+	Players []Standing `json:"players"`
+}
+
+// Standing is the standing of a player in a match.
+type Standing struct {
+	// Player is the index of the player in the match.
+	Player int     `json:"player"`
+	Name   string  `json:"name"`
+	Points float64 `json:"points"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Draws  int     `json:"draws"`
+	// Elo is the rating difference to the other players estimated from
+	// the points, omitted when the player won or lost every game.
+	Elo *float64 `json:"elo,omitempty"`
+	// Crosstable are the points scored against each player,
+	// in the order of the standings.
+	Crosstable []Tally `json:"crosstable"`
+}
+
+// Tally is the points scored in a number of games.
+type Tally struct {
+	Points float64 `json:"points"`
+	Games  int     `json:"games"`
+}
+
+// Standings returns the standings of the match from its finished games.
+//
+// Players are ranked by points, then by order in the match.
+func (j *Journal) Standings() Standings {
+	names := j.Match.Names()
+	players := make([]Standing, len(names))
+	tallies := make([][]Tally, len(names))
+	for i, name := range names {
+		players[i] = Standing{Player: i, Name: name}
+		tallies[i] = make([]Tally, len(names))
+	}
+
+	for n, record := range j.Games {
+		p := j.Schedule[n]
+
+		var white float64
+		switch chess.Outcome(record.Result) {
+		case chess.WhiteWon:
+			white = 1
+			players[p.White].Wins++
+			players[p.Black].Losses++
+		case chess.BlackWon:
+			players[p.White].Losses++
+			players[p.Black].Wins++
+		case chess.Draw:
+			white = 0.5
+			players[p.White].Draws++
+			players[p.Black].Draws++
+		case chess.NoOutcome:
+			continue
+		}
+
+		tallies[p.White][p.Black].Points += white
+		tallies[p.White][p.Black].Games++
+		tallies[p.Black][p.White].Points += 1 - white
+		tallies[p.Black][p.White].Games++
+	}
+
+	for i := range players {
+		s := &players[i]
+		s.Games = s.Wins + s.Losses + s.Draws
+		s.Points = float64(s.Wins) + float64(s.Draws)/2
+		s.Elo = elo(s.Points, s.Games)
+	}
+
+	sort.SliceStable(players, func(a, b int) bool {
+		return players[a].Points > players[b].Points
+	})
+
+	for i := range players {
+		for _, opponent := range players {
+			players[i].Crosstable = append(players[i].Crosstable, tallies[players[i].Player][opponent.Player])
+		}
+	}
+
+	return Standings{Players: players}
+}
+
+// elo returns the Elo rating difference estimated from the points scored
+// in a number of games, nil if it cannot be estimated.
+func elo(points float64, games int) *float64 {
+	if games == 0 || points == 0 || points == float64(games) {
+		return nil
+	}
+
+	ratio := points / float64(games)
+	diff := -400 * math.Log10(1/ratio-1)
+	return &diff
+}
@@ -0,0 +1,60 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/leonhfr/cete/pkg/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStandings(t *testing.T) {
+	j := &Journal{
+		Match: Match{Players: []game.Player{{Engine: "a"}, {Engine: "b"}, {Engine: "c"}}},
+		Schedule: []Pairing{
+			{White: 0, Black: 1},
+			{White: 0, Black: 2},
+			{White: 1, Black: 2},
+			{White: 1, Black: 0},
+		},
+		Games: map[int]game.Record{
+			0: {Result: "0-1"},
+			1: {Result: "1/2-1/2"},
+			2: {Result: "1-0"},
+		},
+	}
+
+	standings := j.Standings()
+	assert.Len(t, standings.Players, 3)
+
+	b, a, c := standings.Players[0], standings.Players[1], standings.Players[2]
+	assert.Equal(t, []Tally{{}, {1, 1}, {1, 1}}, b.Crosstable)
+	assert.Equal(t, []Tally{{0, 1}, {}, {0.5, 1}}, a.Crosstable)
+	assert.Equal(t, []Tally{{0, 1}, {0.5, 1}, {}}, c.Crosstable)
+
+	assert.Equal(t, Standing{Player: 1, Name: "b", Points: 2, Games: 2, Wins: 2, Crosstable: b.Crosstable}, b)
+	assert.Equal(t, "a", a.Name)
+	assert.Equal(t, 0.5, a.Points)
+	assert.Equal(t, 2, a.Games)
+	if assert.NotNil(t, a.Elo) {
+		assert.InDelta(t, -190.85, *a.Elo, 0.01)
+	}
+	assert.Equal(t, "c", c.Name)
+	assert.Equal(t, 0.5, c.Points)
+}
+
+func TestElo(t *testing.T) {
+	tests := []struct {
+		points float64
+		games  int
+		want   *float64
+	}{
+		{0, 0, nil},
+		{0, 2, nil},
+		{2, 2, nil},
+		{1, 2, new(float64)},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, elo(tt.points, tt.games))
+	}
+}
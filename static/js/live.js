@@ -0,0 +1,18 @@
+// Helpers shared by the pages of the live view.
+
+// subscribeURL returns the WebSocket URL of the subscribe endpoint.
+function subscribeURL(path) {
+  const scheme = location.protocol === "https:" ? "wss" : "ws";
+  return `${scheme}://${location.host}${path}`;
+}
+
+async function post(path) {
+  try {
+    const res = await fetch(path, { method: "POST", body: "" });
+    if (!res.ok) {
+      console.log(`${path} failed: ${res.status} ${res.statusText}`);
+    }
+  } catch (err) {
+    console.log(`${path} failed: ${err}`);
+  }
+}